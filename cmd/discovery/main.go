@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/config"
+	"github.com/fkcurrie/fluidnc-led-golang/internal/discovery"
+)
+
+func main() {
+	configPath := flag.String("config", "config.json", "Path to configuration file")
+	once := flag.Bool("once", false, "Scan once, print the devices found and exit")
+	flag.Parse()
+
+	// Load configuration, falling back to the defaults in containers
+	cfg, err := config.LoadConfig(*configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		cfg, err = config.DefaultConfig(), nil
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Environment overrides, as set by docker-compose
+	if v, ok := envInt("DISCOVERY_SCAN_INTERVAL"); ok {
+		cfg.Discovery.ScanInterval = v
+	}
+	if v, ok := envInt("DISCOVERY_TIMEOUT"); ok {
+		cfg.Discovery.Timeout = v
+	}
+	if v := os.Getenv("DISCOVERY_REGISTRY"); v != "" {
+		cfg.Discovery.Registry = v
+	}
+	if v := os.Getenv("DISCOVERY_NETWORKS"); v != "" {
+		cfg.Discovery.Networks = strings.Split(v, ",")
+	}
+
+	registry, err := discovery.LoadRegistry(cfg.Discovery.Registry)
+	if err != nil {
+		log.Fatalf("Failed to load registry: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := discovery.NewService(cfg.Discovery, registry)
+
+	if *once {
+		start := time.Now()
+		if err := service.Scan(ctx); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+		for _, device := range registry.Devices() {
+			if device.LastSeen.Before(start) {
+				continue
+			}
+			log.Printf("%s:%d %s %s %s %s", device.IPAddress, device.Port, device.Hostname, device.MAC, device.Firmware, device.Version)
+		}
+		return
+	}
+
+	// Handle shutdown gracefully
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		log.Println("Shutting down...")
+		cancel()
+	}()
+
+	if err := service.Run(ctx); err != nil {
+		log.Fatalf("Discovery failed: %v", err)
+	}
+}
+
+// envInt returns the integer value of an environment variable
+func envInt(name string) (int, bool) {
+	v, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
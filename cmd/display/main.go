@@ -13,25 +13,78 @@ import (
 
 	"github.com/fkcurrie/fluidnc-led-golang/internal/config"
 	"github.com/fkcurrie/fluidnc-led-golang/internal/display"
-	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/internal/fluidnc"
 )
 
 var (
-	port = flag.Int("port", 8080, "Port to listen on")
+	port       = flag.Int("port", 8080, "Port to listen on")
+	configPath = flag.String("config", "config.json", "Path to configuration file")
+	pitch      = flag.Int("preview-pitch", 10, "Size of one LED in the browser preview, in pixels")
+	record     = flag.Int("record", 1200, "Number of recent frames to keep for GIF export, 0 to disable")
+	gifPath    = flag.String("gif", "", "Write the recorded session to this GIF file on shutdown")
 )
 
 func main() {
 	flag.Parse()
 
 	// Load configuration
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
 	// Create context that can be cancelled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Create the matrix, mirrored to the browser preview
+	matrix, err := display.NewMatrix(&cfg.Display)
+	if err != nil {
+		log.Fatalf("Failed to create matrix: %v", err)
+	}
+	mirror := display.NewMirror(matrix, cfg.Display.Width, cfg.Display.Height)
+	defer mirror.Close()
+
+	// Record shown frames for export as an animated GIF
+	var recorder *display.Recorder
+	if *record > 0 {
+		recorder = display.NewRecorder(*record)
+		mirror.Tap(recorder.Record)
+	}
+
 	// Create renderer
-	renderer := display.NewRenderer(cfg.Display)
+	renderer, err := display.Setup(&cfg.Display)
+	if err != nil {
+		log.Fatalf("Failed to set up display: %v", err)
+	}
+	renderer.SetMatrix(mirror)
+	go func() {
+		if err := renderer.Start(ctx); err != nil && err != context.Canceled {
+			log.Printf("Renderer stopped: %v", err)
+		}
+	}()
+
+	// Show the configured machines; without any the preview stays on the
+	// splash page
+	manager, err := fluidnc.NewManager(cfg.MachineConfigs())
+	if err != nil {
+		log.Printf("Not connecting to any machine: %v", err)
+	} else {
+		defer manager.Close()
+		if err := manager.Start(ctx); err != nil {
+			log.Fatalf("Failed to start FluidNC clients: %v", err)
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-manager.Changed():
+					renderer.Update(manager.Machines()...)
+				}
+			}
+		}()
+	}
 
 	// Create HTTP server
 	mux := http.NewServeMux()
@@ -39,6 +92,10 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	if recorder != nil {
+		mux.Handle("/record.gif", recorder.Handler(*pitch))
+	}
+	mux.Handle("/", display.PreviewHandler(mirror, *pitch))
 
 	// Start HTTP server
 	server := &http.Server{
@@ -68,4 +125,10 @@ func main() {
 
 	// Cancel context
 	cancel()
-} 
\ No newline at end of file
+
+	if recorder != nil && *gifPath != "" {
+		if err := recorder.WriteFile(*gifPath, *pitch); err != nil {
+			log.Printf("Failed to write recording: %v", err)
+		}
+	}
+}
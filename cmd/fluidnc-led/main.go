@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
@@ -8,8 +9,9 @@ import (
 	"syscall"
 
 	"github.com/fcurrie/fluidnc-led-golang/internal/config"
+	"github.com/fcurrie/fluidnc-led-golang/internal/discovery"
 	"github.com/fcurrie/fluidnc-led-golang/internal/display"
-	"github.com/fcurrie/fluidnc-led-golang/internal/grbl"
+	"github.com/fcurrie/fluidnc-led-golang/internal/fluidnc"
 )
 
 // Version information
@@ -20,6 +22,9 @@ const (
 func main() {
 	configPath := flag.String("config", "config.json", "Path to configuration file")
 	version := flag.Bool("version", false, "Display version information")
+	gifPath := flag.String("gif", "", "Record the session and write it to this GIF file on shutdown")
+	record := flag.Int("record", 1200, "Number of recent frames to keep for -gif")
+	pitch := flag.Int("gif-pitch", 10, "Size of one LED in the GIF, in pixels")
 	flag.Parse()
 
 	// Display version if requested
@@ -35,29 +40,88 @@ func main() {
 	}
 
 	// Create display
-	disp, err := display.NewDisplay(cfg.Display)
+	matrix, err := display.NewMatrix(&cfg.Display)
 	if err != nil {
 		log.Fatalf("Failed to create display: %v", err)
 	}
-	defer disp.Close()
+	defer matrix.Close()
+	renderer, err := display.Setup(&cfg.Display)
+	if err != nil {
+		log.Fatalf("Failed to set up display: %v", err)
+	}
+	renderer.SetMatrix(matrix)
+
+	// Record shown frames, with the machines' data, for export as a GIF
+	var recorder *display.Recorder
+	if *gifPath != "" && *record > 0 {
+		recorder = display.NewRecorder(*record)
+		mirror := display.NewMirror(matrix, cfg.Display.Width, cfg.Display.Height)
+		mirror.Tap(recorder.Record)
+		renderer.SetMatrix(mirror)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Create GRBL client
-	client, err := grbl.NewClient(cfg.GRBL)
+	// Create a FluidNC client for every configured machine
+	manager, err := fluidnc.NewManager(cfg.MachineConfigs())
 	if err != nil {
-		log.Fatalf("Failed to create GRBL client: %v", err)
+		log.Fatalf("Failed to configure machines: %v", err)
 	}
-	defer client.Close()
+	defer manager.Close()
 
 	// Handle shutdown gracefully
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start GRBL client
-	if err := client.Start(); err != nil {
-		log.Fatalf("Failed to start GRBL client: %v", err)
+	// Start FluidNC clients
+	if err := manager.Start(ctx); err != nil {
+		log.Fatalf("Failed to start FluidNC clients: %v", err)
+	}
+
+	// Render every change of the machines' data
+	go func() {
+		if err := renderer.Start(ctx); err != nil && err != context.Canceled {
+			log.Printf("Renderer stopped: %v", err)
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-manager.Changed():
+				renderer.Update(manager.Machines()...)
+			}
+		}
+	}()
+
+	// Follow machines that move to a new address when discovery is enabled
+	if cfg.Discovery.ScanInterval > 0 {
+		registry, err := discovery.LoadRegistry(cfg.Discovery.Registry)
+		if err != nil {
+			log.Fatalf("Failed to load device registry: %v", err)
+		}
+		service := discovery.NewService(cfg.Discovery, registry)
+		go func() {
+			if err := service.Run(ctx); err != nil {
+				log.Printf("Discovery stopped: %v", err)
+			}
+		}()
+		go func() {
+			for change := range service.Changes() {
+				manager.Relocate(change.OldAddress, change.Device.IPAddress)
+			}
+		}()
 	}
 
 	// Wait for shutdown signal
 	<-sigChan
 	log.Println("Shutting down...")
-} 
\ No newline at end of file
+
+	if recorder != nil {
+		if err := recorder.WriteFile(*gifPath, *pitch); err != nil {
+			log.Printf("Failed to write recording: %v", err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"image/color"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
 	"github.com/warthog618/go-gpiocdev"
 )
 
@@ -26,611 +28,8 @@ const (
 	MIN_BRIGHTNESS = 0.2        // Minimum brightness level to maintain even at low intensity
 )
 
-// ComicFont defines a larger 8x12 font with Comic Sans-like rounded styling
-var comicFont = map[rune][]byte{
-	'A': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b11111111,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b00000000,
-		0b00000000,
-	},
-	'B': {
-		0b11111100,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b01111100,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b11111100,
-		0b00000000,
-		0b00000000,
-	},
-	'C': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000000,
-		0b11000000,
-		0b11000000,
-		0b11000000,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'D': {
-		0b11111000,
-		0b01101100,
-		0b01100110,
-		0b01100011,
-		0b01100011,
-		0b01100011,
-		0b01100011,
-		0b01100110,
-		0b01101100,
-		0b11111000,
-		0b00000000,
-		0b00000000,
-	},
-	'E': {
-		0b11111110,
-		0b01100010,
-		0b01100000,
-		0b01100000,
-		0b01111100,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100010,
-		0b11111110,
-		0b00000000,
-		0b00000000,
-	},
-	'F': {
-		0b11111110,
-		0b01100010,
-		0b01100000,
-		0b01100000,
-		0b01111100,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b11110000,
-		0b00000000,
-		0b00000000,
-	},
-	'G': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000000,
-		0b11000000,
-		0b11001111,
-		0b11000011,
-		0b11000011,
-		0b01100111,
-		0b00111011,
-		0b00000000,
-		0b00000000,
-	},
-	'H': {
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11111111,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b00000000,
-		0b00000000,
-	},
-	'I': {
-		0b01111100,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b01111100,
-		0b00000000,
-		0b00000000,
-	},
-	'J': {
-		0b00011110,
-		0b00001100,
-		0b00001100,
-		0b00001100,
-		0b00001100,
-		0b00001100,
-		0b11001100,
-		0b11001100,
-		0b01101100,
-		0b00111000,
-		0b00000000,
-		0b00000000,
-	},
-	'K': {
-		0b11100111,
-		0b01100110,
-		0b01100100,
-		0b01101000,
-		0b01110000,
-		0b01111000,
-		0b01101100,
-		0b01100110,
-		0b01100011,
-		0b11100001,
-		0b00000000,
-		0b00000000,
-	},
-	'L': {
-		0b11110000,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b01100001,
-		0b01100011,
-		0b11111111,
-		0b00000000,
-		0b00000000,
-	},
-	'M': {
-		0b11000011,
-		0b11100111,
-		0b11111111,
-		0b11011011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b00000000,
-		0b00000000,
-	},
-	'N': {
-		0b11000011,
-		0b11100011,
-		0b11110011,
-		0b11011011,
-		0b11001111,
-		0b11000111,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b00000000,
-		0b00000000,
-	},
-	'O': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'P': {
-		0b11111100,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b01111100,
-		0b01100000,
-		0b01100000,
-		0b01100000,
-		0b11110000,
-		0b00000000,
-		0b00000000,
-	},
-	'Q': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11001011,
-		0b11000111,
-		0b01100110,
-		0b00111101,
-		0b00000000,
-		0b00000000,
-	},
-	'R': {
-		0b11111100,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b01111100,
-		0b01101100,
-		0b01100110,
-		0b01100110,
-		0b01100110,
-		0b11100110,
-		0b00000000,
-		0b00000000,
-	},
-	'S': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b01100000,
-		0b00111000,
-		0b00001100,
-		0b00000110,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'T': {
-		0b11111111,
-		0b10110110,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b01111000,
-		0b00000000,
-		0b00000000,
-	},
-	'U': {
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'V': {
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00011000,
-		0b00000000,
-		0b00000000,
-	},
-	'W': {
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11000011,
-		0b11011011,
-		0b11111111,
-		0b01100110,
-		0b01100110,
-		0b00000000,
-		0b00000000,
-	},
-	'X': {
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00011000,
-		0b00011000,
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b00000000,
-		0b00000000,
-	},
-	'Y': {
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00011000,
-		0b00011000,
-		0b00011000,
-		0b00011000,
-		0b00011000,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'Z': {
-		0b11111111,
-		0b11000111,
-		0b10001100,
-		0b00011000,
-		0b00110000,
-		0b01100000,
-		0b11000000,
-		0b11000011,
-		0b11100111,
-		0b11111111,
-		0b00000000,
-		0b00000000,
-	},
-	'0': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000111,
-		0b11001111,
-		0b11011011,
-		0b11110011,
-		0b11100011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'1': {
-		0b00110000,
-		0b01110000,
-		0b11110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b11111100,
-		0b00000000,
-		0b00000000,
-	},
-	'2': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b00000011,
-		0b00000110,
-		0b00001100,
-		0b00011000,
-		0b00110000,
-		0b01100000,
-		0b11111111,
-		0b00000000,
-		0b00000000,
-	},
-	'3': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b00000011,
-		0b00011110,
-		0b00011110,
-		0b00000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'4': {
-		0b00001100,
-		0b00011100,
-		0b00111100,
-		0b01101100,
-		0b11001100,
-		0b11111111,
-		0b00001100,
-		0b00001100,
-		0b00001100,
-		0b00011110,
-		0b00000000,
-		0b00000000,
-	},
-	'5': {
-		0b11111111,
-		0b11000000,
-		0b11000000,
-		0b11000000,
-		0b11111100,
-		0b00000110,
-		0b00000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'6': {
-		0b00111100,
-		0b01100110,
-		0b11000000,
-		0b11000000,
-		0b11111100,
-		0b11000110,
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'7': {
-		0b11111111,
-		0b11000011,
-		0b10000110,
-		0b00001100,
-		0b00011000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00110000,
-		0b00000000,
-		0b00000000,
-	},
-	'8': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b01111110,
-		0b01111110,
-		0b11000011,
-		0b11000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	'9': {
-		0b00111100,
-		0b01100110,
-		0b11000011,
-		0b11000011,
-		0b01100111,
-		0b00111111,
-		0b00000011,
-		0b00000011,
-		0b01100110,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	' ': {
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-	},
-	'!': {
-		0b00011000,
-		0b00111100,
-		0b00111100,
-		0b00111100,
-		0b00111100,
-		0b00011000,
-		0b00011000,
-		0b00000000,
-		0b00011000,
-		0b00011000,
-		0b00000000,
-		0b00000000,
-	},
-	'.': {
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00111100,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-	},
-	',': {
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00111000,
-		0b00111000,
-		0b00011000,
-		0b00110000,
-		0b00000000,
-	},
-	':': {
-		0b00000000,
-		0b00000000,
-		0b00111100,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-		0b00111100,
-		0b00111100,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-	},
-	'-': {
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b11111111,
-		0b11111111,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-		0b00000000,
-	},
-	'+': {
-		0b00000000,
-		0b00000000,
-		0b00011000,
-		0b00011000,
-		0b00011000,
-		0b11111111,
-		0b11111111,
-		0b00011000,
-		0b00011000,
-		0b00011000,
-		0b00000000,
-		0b00000000,
-	},
-};
+// comicFont is the 8x12 font text is scrolled in
+var comicFont = font.Comic()
 
 // HUB75 pin configuration for Adafruit RGB Matrix Bonnet
 type HUB75Config struct {
@@ -753,7 +152,7 @@ func (fb *FrameBuffer) SetPixel(x, y int, r, g, b byte) {
 }
 
 // RenderText renders text centered on the display
-func (fb *FrameBuffer) RenderText(text string, offsetX int, color [3]byte) {
+func (fb *FrameBuffer) RenderText(text string, offsetX int, rgb [3]byte) {
 	fb.Clear()
 	
 	// Calculate total text width
@@ -765,51 +164,14 @@ func (fb *FrameBuffer) RenderText(text string, offsetX int, color [3]byte) {
 	// Calculate vertical position - center the text vertically
 	startY := (DISPLAY_HEIGHT - FONT_HEIGHT) / 2
 	
-	// Draw each character
-	x := startX
-	for _, char := range text {
-		// Skip if the entire character would be off-screen
-		if x + FONT_WIDTH < 0 {
-			x += FONT_WIDTH + CHAR_SPACING
-			continue
-		}
-		if x >= DISPLAY_WIDTH {
-			break
-		}
-		
-		// Get the font data for this character
-		fontData, exists := comicFont[char]
-		if !exists {
-			// Use space for unknown characters
-			fontData = comicFont[' ']
-		}
-		
-		// Draw each column of the character
-		for col := 0; col < FONT_WIDTH; col++ {
-			// Skip if this column is off-screen
-			if x + col < 0 || x + col >= DISPLAY_WIDTH {
-				continue
-			}
-			
-			// Draw each pixel in the column
-			for row := 0; row < FONT_HEIGHT; row++ {
-				// Check if this pixel should be on
-				if row < len(fontData) && (fontData[row] & (0x80 >> col)) != 0 {
-					// Calculate the final position on the display
-					displayY := startY + row
-					displayX := x + col
-					
-					// Set pixel
-					if displayY >= 0 && displayY < DISPLAY_HEIGHT {
-						fb.SetPixel(displayX, displayY, color[0], color[1], color[2])
-					}
-				}
-			}
-		}
-		
-		// Move to the next character position
-		x += FONT_WIDTH + CHAR_SPACING
-	}
+	// Off-screen pixels are clipped by SetPixel
+	comicFont.Draw(fb, startX, startY, text, color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 255})
+}
+
+// Set sets a pixel so the frame buffer can be drawn on by the font package
+func (fb *FrameBuffer) Set(x, y int, c color.Color) {
+	r, g, b, _ := c.RGBA()
+	fb.SetPixel(x, y, byte(r>>8), byte(g>>8), byte(b>>8))
 }
 
 // RenderFrame renders a full frame to the LED matrix
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 
 	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
 )
@@ -11,6 +12,17 @@ import (
 type Config struct {
 	Display types.DisplayConfig `json:"display"`
 	GRBL    types.FluidNCConfig `json:"grbl"`
+	// Machines lists several controllers to monitor; when empty GRBL is used
+	Machines  []types.FluidNCConfig `json:"machines"`
+	Discovery types.DiscoveryConfig `json:"discovery"`
+}
+
+// MachineConfigs returns the controllers to monitor
+func (c *Config) MachineConfigs() []types.FluidNCConfig {
+	if len(c.Machines) > 0 {
+		return c.Machines
+	}
+	return []types.FluidNCConfig{c.GRBL}
 }
 
 // LoadConfig loads the configuration from a file
@@ -26,20 +38,52 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// Files named in the configuration ship next to it, so find them from
+	// there rather than from the working directory
+	dir := filepath.Dir(path)
+	display := &config.Display
+	display.Layout = resolvePath(dir, display.Layout)
+	display.Simulator.Dir = resolvePath(dir, display.Simulator.Dir)
+	display.Splash.Path = resolvePath(dir, display.Splash.Path)
+	for i := range display.Icons {
+		display.Icons[i].Path = resolvePath(dir, display.Icons[i].Path)
+	}
+	for i := range display.Fonts {
+		display.Fonts[i].Path = resolvePath(dir, display.Fonts[i].Path)
+	}
+	config.Discovery.Registry = resolvePath(dir, config.Discovery.Registry)
+
 	return &config, nil
 }
 
+// resolvePath returns a relative path as relative to dir
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Display: types.DisplayConfig{
-			Width:      32,
-			Height:     8,
-			Brightness: 64,
+			Width:          32,
+			Height:         8,
+			Brightness:     64,
+			MachineView:    "rotate",
+			RotateInterval: 5,
 		},
 		GRBL: types.FluidNCConfig{
-			Host: "localhost",
-			Port: 23,
+			Host:              "localhost",
+			Port:              23,
+			ReconnectInterval: 5,
+			StatusInterval:    0.5,
+		},
+		Discovery: types.DiscoveryConfig{
+			ScanInterval: 300,
+			Timeout:      5,
+			Registry:     "devices.json",
 		},
 	}
 } 
\ No newline at end of file
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadConfigPaths tests that relative paths are resolved against the
+// directory of the configuration file
+func TestLoadConfigPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	data := `{
+		"display": {
+			"layout": "layout.yaml",
+			"simulator": {"dir": "frames"},
+			"splash": {"path": "logo.svg"},
+			"icons": [{"name": "spindle", "path": "icons/spindle.svg"}],
+			"fonts": [{"name": "big", "path": "/usr/share/fonts/big.ttf"}, {"name": "small", "path": "fonts/5x7.bdf"}]
+		},
+		"discovery": {"registry": "devices.json"}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"layout", cfg.Display.Layout, filepath.Join(dir, "layout.yaml")},
+		{"simulator dir", cfg.Display.Simulator.Dir, filepath.Join(dir, "frames")},
+		{"splash", cfg.Display.Splash.Path, filepath.Join(dir, "logo.svg")},
+		{"icon", cfg.Display.Icons[0].Path, filepath.Join(dir, "icons", "spindle.svg")},
+		{"absolute font", cfg.Display.Fonts[0].Path, "/usr/share/fonts/big.ttf"},
+		{"relative font", cfg.Display.Fonts[1].Path, filepath.Join(dir, "fonts", "5x7.bdf")},
+		{"registry", cfg.Discovery.Registry, filepath.Join(dir, "devices.json")},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestLoadConfigEmptyPaths tests that unset paths stay unset
+func TestLoadConfigEmptyPaths(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"display": {"width": 64}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Display.Layout != "" || cfg.Display.Splash.Path != "" || cfg.Discovery.Registry != "" {
+		t.Errorf("unset paths resolved: %+v, %+v", cfg.Display, cfg.Discovery)
+	}
+}
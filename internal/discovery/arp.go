@@ -0,0 +1,48 @@
+package discovery
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// arpTable is the kernel's neighbour cache on Linux; other systems have no
+// such file and devices are tracked by address alone
+var arpTable = "/proc/net/arp"
+
+// lookupMAC returns the hardware address the neighbour cache holds for ip,
+// or "" if it is unknown. Fingerprinting a device fills the cache, so this
+// is called after talking to it.
+func lookupMAC(ip string) string {
+	f, err := os.Open(arpTable)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return parseARP(f, ip)
+}
+
+// parseARP finds the hardware address of ip in a /proc/net/arp listing
+func parseARP(r io.Reader, ip string) string {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Header
+	for scanner.Scan() {
+		// IP address, HW type, Flags, HW address, Mask, Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != ip {
+			continue
+		}
+		// Flags of 0x0 mark an incomplete entry
+		if fields[2] == "0x0" {
+			return ""
+		}
+		mac, err := net.ParseMAC(fields[3])
+		if err != nil || mac.String() == "00:00:00:00:00:00" {
+			return ""
+		}
+		return mac.String()
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package discovery
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseARP tests hardware address lookup in the neighbour cache
+func TestParseARP(t *testing.T) {
+	const table = `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         a4:91:b1:00:11:22     *        wlan0
+192.168.1.20     0x1         0x2         24:0A:C4:5E:6F:70     *        wlan0
+192.168.1.30     0x1         0x0         00:00:00:00:00:00     *        wlan0
+`
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "192.168.1.20", want: "24:0a:c4:5e:6f:70"},
+		{ip: "192.168.1.1", want: "a4:91:b1:00:11:22"},
+		{ip: "192.168.1.30", want: ""},
+		{ip: "192.168.1.40", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := parseARP(strings.NewReader(table), tt.ip); got != tt.want {
+				t.Errorf("parseARP(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
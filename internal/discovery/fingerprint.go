@@ -0,0 +1,108 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/fluidnc"
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// webUIPort is where FluidNC serves its web UI and /command endpoint
+const webUIPort = 80
+
+// errNotFluidNC is returned when a web server answers without an [ESP800]
+// report, e.g. a printer or NAS
+var errNotFluidNC = errors.New("not a FluidNC web UI")
+
+// identify fingerprints the device of a result and records what it is
+func (s *Scanner) identify(ctx context.Context, result *ScanResult) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
+	defer cancel()
+
+	info, err := fingerprint(ctx, result.IPAddress, result.Port)
+	if err != nil {
+		result.Valid = false
+		result.Error = err
+		return
+	}
+
+	result.Valid = true
+	result.MAC = lookupMAC(result.IPAddress)
+	result.Firmware = info.Firmware
+	result.Version = info.Version
+	if info.Hostname != "" {
+		result.Hostname = info.Hostname
+	}
+}
+
+// answersAs reports whether the device at address:port still identifies as
+// the registry device id
+func (s *Scanner) answersAs(ctx context.Context, id, address string, port int) bool {
+	probe := ScanResult{IPAddress: address, Port: port}
+	s.identify(ctx, &probe)
+	return probe.Valid && deviceID(probe) == id
+}
+
+// fingerprint identifies the controller at host:port. The web UI is asked
+// for [ESP800] first since that is cheap and rejects other web servers by
+// content; otherwise the WebSocket is probed for a banner or status report.
+func fingerprint(ctx context.Context, host string, port int) (types.ControllerInfo, error) {
+	ports := []int{port}
+	if port != webUIPort {
+		ports = append(ports, webUIPort)
+	}
+	for _, p := range ports {
+		if info, err := fetchESP800(ctx, host, p); err == nil {
+			return info, nil
+		}
+	}
+
+	return fluidnc.Identify(ctx, types.FluidNCConfig{
+		Host:      host,
+		Port:      port,
+		Transport: fluidnc.TransportWebSocket,
+	})
+}
+
+// fetchESP800 asks the web UI for the [ESP800] firmware report
+func fetchESP800(ctx context.Context, host string, port int) (types.ControllerInfo, error) {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/command",
+		RawQuery: "plain=" + url.QueryEscape("[ESP800]"),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return types.ControllerInfo{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return types.ControllerInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.ControllerInfo{}, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return types.ControllerInfo{}, err
+	}
+
+	for _, line := range strings.Split(string(body), "\n") {
+		if info, ok := fluidnc.ParseESP800(strings.TrimSpace(line)); ok && info.IsFluidNC() {
+			return info, nil
+		}
+	}
+	return types.ControllerInfo{}, errNotFluidNC
+}
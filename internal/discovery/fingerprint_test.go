@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/gorilla/websocket"
+)
+
+// webSocketController serves a FluidNC-like WebSocket that answers [ESP800]
+// and status queries
+func webSocketController(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	var upgrader websocket.Upgrader
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		reply := "<Idle|MPos:0,0,0|FS:0,0>\n"
+		if strings.HasPrefix(string(message), "[ESP800]") {
+			reply = "FW version: FluidNC v3.7.8 (wifi) # hostname:laser # axis:3\nok\n"
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(reply))
+	}
+}
+
+// TestFingerprint tests identifying FluidNC and rejecting other web servers
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantErr  bool
+		hostname string
+	}{
+		{
+			name: "web UI",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/command" && r.URL.Query().Get("plain") == "[ESP800]" {
+					w.Write([]byte("FW version: FluidNC v3.7.8 (wifi) # hostname:router # axis:4"))
+					return
+				}
+				http.NotFound(w, r)
+			},
+			hostname: "router",
+		},
+		{
+			name:     "WebSocket",
+			handler:  webSocketController,
+			hostname: "laser",
+		},
+		{
+			name: "printer",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html><title>LaserJet</title></html>"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			host, portStr, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+			port, _ := strconv.Atoi(portStr)
+
+			s := NewScanner(types.DiscoveryConfig{Timeout: 1})
+			result := ScanResult{IPAddress: host, Port: port}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			s.identify(ctx, &result)
+
+			if tt.wantErr {
+				if result.Valid || result.Error == nil {
+					t.Errorf("identify() = %+v, want rejected", result)
+				}
+				return
+			}
+			if !result.Valid || result.Firmware != types.FirmwareFluidNC ||
+				result.Version != "v3.7.8" || result.Hostname != tt.hostname {
+				t.Errorf("identify() = %+v", result)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DNS record types and classes used by mDNS/DNS-SD
+const (
+	dnsTypeA   = 1
+	dnsTypePTR = 12
+	dnsTypeSRV = 33
+
+	dnsClassIN = 1
+	// dnsClassTop is the unicast-response bit in questions and the
+	// cache-flush bit in answers
+	dnsClassTop = 0x8000
+)
+
+// DefaultService is the DNS-SD service FluidNC advertises its web UI as
+const DefaultService = "_http._tcp.local."
+
+// mdnsGroup is the IPv4 mDNS multicast address
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Browser discovers devices that advertise themselves over multicast DNS
+type Browser struct {
+	// Group is the address queries are sent to
+	Group *net.UDPAddr
+	// Service is the DNS-SD service type to browse
+	Service string
+}
+
+// NewBrowser creates a browser for FluidNC web UI advertisements
+func NewBrowser() *Browser {
+	return &Browser{
+		Group:   mdnsGroup,
+		Service: DefaultService,
+	}
+}
+
+// Browse queries for the service and collects answers until the context is
+// done. Results are unverified candidates with Valid unset. Queries are
+// sent from an ephemeral port, so responders answer by unicast (RFC 6762
+// section 6.7) and no multicast membership is needed.
+func (b *Browser) Browse(ctx context.Context) ([]ScanResult, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mDNS socket: %w", err)
+	}
+	defer conn.Close()
+
+	// Unblock the read loop when the context ends
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetReadDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	service := canonicalName(b.Service)
+	if _, err := conn.WriteToUDP(packQuery(service, dnsTypePTR), b.Group); err != nil {
+		return nil, fmt.Errorf("failed to send mDNS query: %w", err)
+	}
+
+	cache := newMDNSCache()
+	queried := make(map[string]bool)
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return cache.results(service), fmt.Errorf("failed to read mDNS response: %w", err)
+		}
+
+		records, err := parseMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		cache.add(records, from.IP)
+
+		// Ask for the address of any host that was announced without one
+		for _, host := range cache.unresolved() {
+			if queried[host] {
+				continue
+			}
+			queried[host] = true
+			conn.WriteToUDP(packQuery(host, dnsTypeA), b.Group)
+		}
+	}
+
+	return cache.results(service), nil
+}
+
+// dnsRecord is a resource record from an mDNS response
+type dnsRecord struct {
+	Name string
+	Type uint16
+	// Target is the PTR or SRV target name
+	Target string
+	// Port is the SRV port
+	Port int
+	// IP is the A record address
+	IP net.IP
+}
+
+// srvRecord is the host and port a service instance runs on
+type srvRecord struct {
+	target string
+	port   int
+}
+
+// mdnsCache assembles PTR, SRV and A records into services
+type mdnsCache struct {
+	instances map[string][]string
+	services  map[string]srvRecord
+	addresses map[string]net.IP
+	// sources records the address each instance was announced from
+	sources map[string]net.IP
+}
+
+// newMDNSCache creates an empty cache
+func newMDNSCache() *mdnsCache {
+	return &mdnsCache{
+		instances: make(map[string][]string),
+		services:  make(map[string]srvRecord),
+		addresses: make(map[string]net.IP),
+		sources:   make(map[string]net.IP),
+	}
+}
+
+// add records the answers of one response received from source
+func (c *mdnsCache) add(records []dnsRecord, source net.IP) {
+	for _, rr := range records {
+		switch rr.Type {
+		case dnsTypePTR:
+			if !contains(c.instances[rr.Name], rr.Target) {
+				c.instances[rr.Name] = append(c.instances[rr.Name], rr.Target)
+			}
+			c.sources[rr.Target] = source
+		case dnsTypeSRV:
+			c.services[rr.Name] = srvRecord{target: rr.Target, port: rr.Port}
+		case dnsTypeA:
+			c.addresses[rr.Name] = rr.IP
+		}
+	}
+}
+
+// unresolved returns the SRV targets that have no address yet
+func (c *mdnsCache) unresolved() []string {
+	var hosts []string
+	for _, srv := range c.services {
+		if _, ok := c.addresses[srv.target]; !ok && !contains(hosts, srv.target) {
+			hosts = append(hosts, srv.target)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// results returns the resolved instances of a service, sorted by address
+func (c *mdnsCache) results(service string) []ScanResult {
+	var results []ScanResult
+	for _, instance := range c.instances[service] {
+		srv, ok := c.services[instance]
+		if !ok {
+			continue
+		}
+
+		// Fall back to the responder's address if no A record arrived
+		ip := c.addresses[srv.target]
+		if ip == nil {
+			ip = c.sources[instance]
+		}
+		if ip == nil {
+			continue
+		}
+
+		results = append(results, ScanResult{
+			IPAddress: ip.String(),
+			Port:      srv.port,
+			Hostname:  strings.TrimSuffix(srv.target, ".local."),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].IPAddress < results[j].IPAddress
+	})
+	return results
+}
+
+// contains reports whether s is in list
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// canonicalName lower-cases a DNS name and makes it fully qualified
+func canonicalName(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
+
+// packQuery builds a single-question query requesting a unicast response
+func packQuery(name string, qtype uint16) []byte {
+	msg := make([]byte, 12)
+	binary.BigEndian.PutUint16(msg[4:], 1) // QDCOUNT
+	msg = appendName(msg, name)
+	msg = binary.BigEndian.AppendUint16(msg, qtype)
+	return binary.BigEndian.AppendUint16(msg, dnsClassIN|dnsClassTop)
+}
+
+// appendName appends a DNS name in uncompressed label form
+func appendName(msg []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	return append(msg, 0)
+}
+
+// errMalformed is returned for truncated or inconsistent messages
+var errMalformed = errors.New("malformed DNS message")
+
+// parseMessage returns the answer, authority and additional records of a
+// DNS response. Queries and unsupported record types are skipped.
+func parseMessage(msg []byte) ([]dnsRecord, error) {
+	if len(msg) < 12 {
+		return nil, errMalformed
+	}
+	if msg[2]&0x80 == 0 {
+		// Not a response
+		return nil, nil
+	}
+
+	questions := int(binary.BigEndian.Uint16(msg[4:]))
+	count := int(binary.BigEndian.Uint16(msg[6:])) +
+		int(binary.BigEndian.Uint16(msg[8:])) +
+		int(binary.BigEndian.Uint16(msg[10:]))
+
+	off := 12
+	for i := 0; i < questions; i++ {
+		_, next, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		off = next + 4
+	}
+
+	var records []dnsRecord
+	for i := 0; i < count; i++ {
+		name, next, err := readName(msg, off)
+		if err != nil {
+			return nil, err
+		}
+		if next+10 > len(msg) {
+			return nil, errMalformed
+		}
+		rtype := binary.BigEndian.Uint16(msg[next:])
+		class := binary.BigEndian.Uint16(msg[next+2:]) &^ dnsClassTop
+		length := int(binary.BigEndian.Uint16(msg[next+8:]))
+		data := next + 10
+		off = data + length
+		if off > len(msg) {
+			return nil, errMalformed
+		}
+		if class != dnsClassIN {
+			continue
+		}
+
+		rr := dnsRecord{Name: name, Type: rtype}
+		switch rtype {
+		case dnsTypePTR:
+			if rr.Target, _, err = readName(msg, data); err != nil {
+				return nil, err
+			}
+		case dnsTypeSRV:
+			if length < 7 {
+				return nil, errMalformed
+			}
+			rr.Port = int(binary.BigEndian.Uint16(msg[data+4:]))
+			if rr.Target, _, err = readName(msg, data+6); err != nil {
+				return nil, err
+			}
+		case dnsTypeA:
+			if length != 4 {
+				return nil, errMalformed
+			}
+			rr.IP = net.IPv4(msg[data], msg[data+1], msg[data+2], msg[data+3])
+		default:
+			continue
+		}
+		records = append(records, rr)
+	}
+
+	return records, nil
+}
+
+// readName reads a possibly compressed name at off and returns it with the
+// offset just past it
+func readName(msg []byte, off int) (string, int, error) {
+	var labels []string
+	end := -1
+	for jumps := 0; ; {
+		if off >= len(msg) {
+			return "", 0, errMalformed
+		}
+		length := int(msg[off])
+		switch {
+		case length == 0:
+			if end < 0 {
+				end = off + 1
+			}
+			return canonicalName(strings.Join(labels, ".")), end, nil
+		case length&0xC0 == 0xC0:
+			if off+1 >= len(msg) || jumps > 16 {
+				return "", 0, errMalformed
+			}
+			if end < 0 {
+				end = off + 2
+			}
+			off = int(binary.BigEndian.Uint16(msg[off:]) & 0x3FFF)
+			jumps++
+		default:
+			if off+1+length > len(msg) {
+				return "", 0, errMalformed
+			}
+			labels = append(labels, string(msg[off+1:off+1+length]))
+			off += 1 + length
+		}
+	}
+}
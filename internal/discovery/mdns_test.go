@@ -0,0 +1,124 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// packResponse builds an mDNS response carrying the given records
+func packResponse(records []dnsRecord) []byte {
+	msg := make([]byte, 12)
+	msg[2] = 0x84 // QR, AA
+	binary.BigEndian.PutUint16(msg[6:], uint16(len(records)))
+
+	for _, rr := range records {
+		var data []byte
+		switch rr.Type {
+		case dnsTypePTR:
+			data = appendName(nil, rr.Target)
+		case dnsTypeSRV:
+			data = make([]byte, 6)
+			binary.BigEndian.PutUint16(data[4:], uint16(rr.Port))
+			data = appendName(data, rr.Target)
+		case dnsTypeA:
+			data = rr.IP.To4()
+		}
+
+		msg = appendName(msg, rr.Name)
+		msg = binary.BigEndian.AppendUint16(msg, rr.Type)
+		msg = binary.BigEndian.AppendUint16(msg, dnsClassIN|dnsClassTop)
+		msg = binary.BigEndian.AppendUint32(msg, 120)
+		msg = binary.BigEndian.AppendUint16(msg, uint16(len(data)))
+		msg = append(msg, data...)
+	}
+	return msg
+}
+
+// startResponder answers each query with the records registered for the
+// queried name
+func startResponder(t *testing.T, answers map[string][]dnsRecord) *net.UDPAddr {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			name, _, err := readName(buf[:n], 12)
+			if err != nil {
+				continue
+			}
+			if records, ok := answers[name]; ok {
+				conn.WriteToUDP(packResponse(records), from)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+// TestBrowse tests resolving an advertisement with and without an A record
+func TestBrowse(t *testing.T) {
+	addr := startResponder(t, map[string][]dnsRecord{
+		DefaultService: {
+			{Name: DefaultService, Type: dnsTypePTR, Target: "router._http._tcp.local."},
+			{Name: "router._http._tcp.local.", Type: dnsTypeSRV, Target: "router.local.", Port: 80},
+			{Name: "router.local.", Type: dnsTypeA, IP: net.IPv4(192, 168, 1, 20)},
+			{Name: DefaultService, Type: dnsTypePTR, Target: "laser._http._tcp.local."},
+			{Name: "laser._http._tcp.local.", Type: dnsTypeSRV, Target: "laser.local.", Port: 80},
+		},
+		"laser.local.": {
+			{Name: "laser.local.", Type: dnsTypeA, IP: net.IPv4(192, 168, 1, 10)},
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	b := &Browser{Group: addr, Service: "_http._tcp.local"}
+	results, err := b.Browse(ctx)
+	if err != nil {
+		t.Fatalf("Browse() error = %v", err)
+	}
+
+	want := []ScanResult{
+		{IPAddress: "192.168.1.10", Port: 80, Hostname: "laser"},
+		{IPAddress: "192.168.1.20", Port: 80, Hostname: "router"},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Browse() = %+v, want %+v", results, want)
+	}
+}
+
+// TestReadNameCompressed tests decoding of compressed names
+func TestReadNameCompressed(t *testing.T) {
+	msg := make([]byte, 12)
+	msg = appendName(msg, "fluidnc.local.")
+	// "www" followed by a pointer to "local." at offset 20
+	msg = append(msg, 3, 'w', 'w', 'w', 0xC0, 20)
+
+	name, next, err := readName(msg, 27)
+	if err != nil {
+		t.Fatalf("readName() error = %v", err)
+	}
+	if name != "www.local." || next != len(msg) {
+		t.Errorf("readName() = %q, %d", name, next)
+	}
+
+	// A pointer to itself must not loop forever
+	if _, _, err := readName([]byte{0xC0, 0x00}, 0); err == nil {
+		t.Error("readName() of a pointer loop did not return error")
+	}
+}
@@ -0,0 +1,190 @@
+package discovery
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultRegistryPath is used when no registry file is configured
+const defaultRegistryPath = "devices.json"
+
+// Device is a controller known to the registry
+type Device struct {
+	// ID identifies the device across address changes: its MAC, or its
+	// address if the MAC is unknown. Hostnames are not used since every
+	// stock FluidNC calls itself "fluidnc".
+	ID        string    `json:"id"`
+	Hostname  string    `json:"hostname,omitempty"`
+	MAC       string    `json:"mac,omitempty"`
+	IPAddress string    `json:"ip_address"`
+	Port      int       `json:"port"`
+	Firmware  string    `json:"firmware,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// PreviousAddresses lists earlier addresses, oldest first
+	PreviousAddresses []string `json:"previous_addresses,omitempty"`
+}
+
+// Change reports a known device found at a new address
+type Change struct {
+	Device     Device
+	OldAddress string
+}
+
+// Registry is the set of known controllers, persisted to a JSON file
+type Registry struct {
+	path    string
+	mu      sync.Mutex
+	devices map[string]*Device
+}
+
+// LoadRegistry loads a registry from path; a missing file is an empty
+// registry
+func LoadRegistry(path string) (*Registry, error) {
+	if path == "" {
+		path = defaultRegistryPath
+	}
+
+	r := &Registry{
+		path:    path,
+		devices: make(map[string]*Device),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, fmt.Errorf("failed to parse registry %s: %w", path, err)
+	}
+	for i := range devices {
+		r.devices[devices[i].ID] = &devices[i]
+	}
+
+	return r, nil
+}
+
+// deviceID returns the registry ID of a scan result
+func deviceID(result ScanResult) string {
+	if result.MAC != "" {
+		return result.MAC
+	}
+	return result.IPAddress
+}
+
+// Device returns the known device with the given ID
+func (r *Registry) Device(id string) (Device, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.devices[id]
+	if !ok {
+		return Device{}, false
+	}
+	device := *d
+	device.PreviousAddresses = append([]string(nil), d.PreviousAddresses...)
+	return device, true
+}
+
+// Update records a scan result seen at now. It returns a Change if a known
+// device is now at a different address, otherwise nil.
+func (r *Registry) Update(result ScanResult, now time.Time) *Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := deviceID(result)
+	d, ok := r.devices[id]
+	if result.MAC != "" {
+		// A device first seen without its MAC is keyed by its address
+		if old, found := r.devices[result.IPAddress]; found && old.MAC == "" {
+			delete(r.devices, result.IPAddress)
+			switch {
+			case !ok:
+				old.ID = id
+				d, ok = old, true
+				r.devices[id] = d
+			case old.FirstSeen.Before(d.FirstSeen):
+				d.FirstSeen = old.FirstSeen
+			}
+		}
+	}
+	if !ok {
+		d = &Device{ID: id, IPAddress: result.IPAddress, FirstSeen: now}
+		r.devices[id] = d
+	}
+
+	var change *Change
+	if d.IPAddress != result.IPAddress {
+		change = &Change{OldAddress: d.IPAddress}
+		d.PreviousAddresses = append(d.PreviousAddresses, d.IPAddress)
+		d.IPAddress = result.IPAddress
+	}
+
+	d.Hostname = result.Hostname
+	d.MAC = result.MAC
+	d.Port = result.Port
+	d.Firmware = result.Firmware
+	d.Version = result.Version
+	d.LastSeen = now
+
+	if change != nil {
+		change.Device = *d
+		change.Device.PreviousAddresses = append([]string(nil), d.PreviousAddresses...)
+	}
+	return change
+}
+
+// Devices returns every known device sorted by ID
+func (r *Registry) Devices() []Device {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	devices := make([]Device, 0, len(r.devices))
+	for _, d := range r.devices {
+		device := *d
+		device.PreviousAddresses = append([]string(nil), d.PreviousAddresses...)
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID < devices[j].ID
+	})
+	return devices
+}
+
+// Save writes the registry to its file, replacing it atomically
+func (r *Registry) Save() error {
+	data, err := json.MarshalIndent(r.Devices(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), ".registry-*")
+	if err != nil {
+		return fmt.Errorf("failed to save registry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save registry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save registry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("failed to save registry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRegistryUpdate tests first/last seen tracking and address changes
+func TestRegistryUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devices.json")
+	r, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := ScanResult{IPAddress: "192.168.1.20", Port: 80, Hostname: "router", MAC: "24:0a:c4:5e:6f:70", Firmware: "FluidNC", Version: "v3.7.8", Valid: true}
+
+	if change := r.Update(router, start); change != nil {
+		t.Errorf("Update() of a new device = %+v, want nil", change)
+	}
+	if change := r.Update(router, start.Add(time.Minute)); change != nil {
+		t.Errorf("Update() at the same address = %+v, want nil", change)
+	}
+
+	router.IPAddress = "192.168.1.42"
+	change := r.Update(router, start.Add(time.Hour))
+	if change == nil || change.OldAddress != "192.168.1.20" || change.Device.IPAddress != "192.168.1.42" {
+		t.Fatalf("Update() after a DHCP move = %+v", change)
+	}
+
+	want := []Device{{
+		ID:                "24:0a:c4:5e:6f:70",
+		Hostname:          "router",
+		MAC:               "24:0a:c4:5e:6f:70",
+		IPAddress:         "192.168.1.42",
+		Port:              80,
+		Firmware:          "FluidNC",
+		Version:           "v3.7.8",
+		FirstSeen:         start,
+		LastSeen:          start.Add(time.Hour),
+		PreviousAddresses: []string{"192.168.1.20"},
+	}}
+	if got := r.Devices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Devices() = %+v, want %+v", got, want)
+	}
+
+	// The registry survives a restart
+	if err := r.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+	if got := loaded.Devices(); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded Devices() = %+v, want %+v", got, want)
+	}
+}
+
+// TestRegistryIdentity tests that devices sharing the stock hostname are
+// kept apart
+func TestRegistryIdentity(t *testing.T) {
+	r, err := LoadRegistry(filepath.Join(t.TempDir(), "devices.json"))
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	results := []ScanResult{
+		{IPAddress: "192.168.1.20", Port: 80, Hostname: "fluidnc", MAC: "24:0a:c4:00:00:01", Valid: true},
+		{IPAddress: "192.168.1.21", Port: 80, Hostname: "fluidnc", MAC: "24:0a:c4:00:00:02", Valid: true},
+		{IPAddress: "192.168.1.22", Port: 80, Hostname: "fluidnc", Valid: true},
+		{IPAddress: "192.168.1.23", Port: 80, Hostname: "fluidnc", Valid: true},
+	}
+	for _, result := range results {
+		if change := r.Update(result, now); change != nil {
+			t.Errorf("Update(%s) = %+v, want nil", result.IPAddress, change)
+		}
+	}
+
+	var ids []string
+	for _, d := range r.Devices() {
+		ids = append(ids, d.ID)
+	}
+	want := []string{"192.168.1.22", "192.168.1.23", "24:0a:c4:00:00:01", "24:0a:c4:00:00:02"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("device IDs = %v, want %v", ids, want)
+	}
+}
+
+// TestRegistryLearnsMAC tests that a device first seen without a MAC keeps
+// one entry once its MAC is known
+func TestRegistryLearnsMAC(t *testing.T) {
+	r, err := LoadRegistry(filepath.Join(t.TempDir(), "devices.json"))
+	if err != nil {
+		t.Fatalf("LoadRegistry() error = %v", err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := ScanResult{IPAddress: "192.168.1.20", Port: 80, Hostname: "fluidnc", Valid: true}
+	r.Update(router, start)
+
+	router.MAC = "24:0a:c4:5e:6f:70"
+	if change := r.Update(router, start.Add(time.Minute)); change != nil {
+		t.Errorf("Update() with a MAC = %+v, want nil", change)
+	}
+
+	devices := r.Devices()
+	if len(devices) != 1 {
+		t.Fatalf("Devices() = %+v, want one device", devices)
+	}
+	if d := devices[0]; d.ID != router.MAC || d.MAC != router.MAC || !d.FirstSeen.Equal(start) {
+		t.Errorf("device = %+v, want ID %s first seen at %v", d, router.MAC, start)
+	}
+}
@@ -2,23 +2,39 @@ package discovery
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"net"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
 )
 
+// Scan defaults used when the configuration leaves them unset
+const (
+	defaultPort    = 81
+	defaultWorkers = 64
+	defaultTimeout = 2 * time.Second
+)
+
+// maxPrefixBits is the shortest prefix that is scanned; anything larger than
+// a /16 would take hours to sweep
+const maxPrefixBits = 16
+
 // Scanner represents a network scanner for discovering FluidNC devices
 type Scanner struct {
-	config types.DiscoveryConfig
+	config  types.DiscoveryConfig
+	browser *Browser
 }
 
 // NewScanner creates a new network scanner
 func NewScanner(config types.DiscoveryConfig) *Scanner {
 	return &Scanner{
-		config: config,
+		config:  config,
+		browser: NewBrowser(),
 	}
 }
 
@@ -26,21 +42,180 @@ func NewScanner(config types.DiscoveryConfig) *Scanner {
 type ScanResult struct {
 	IPAddress string
 	Port      int
-	Valid     bool
-	Error     error
+	Hostname  string
+	// MAC is the hardware address of the device, if the neighbour cache
+	// knows it
+	MAC      string
+	Firmware string
+	Version  string
+	Valid    bool
+	Error    error
 }
 
-// ScanNetwork scans the network for FluidNC devices
+// ScanNetwork scans the network for FluidNC devices and returns them sorted
+// by address once the scan completes
 func (s *Scanner) ScanNetwork(ctx context.Context) ([]ScanResult, error) {
+	found, err := s.Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ScanResult
+	for result := range found {
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return ipLess(results[i].IPAddress, results[j].IPAddress)
+	})
+	return results, ctx.Err()
+}
+
+// Scan probes every host and port of the configured networks with a bounded
+// pool of workers while browsing mDNS, and streams each FluidNC device as it
+// is identified. A device is reported once even if found several ways. The
+// channel is closed when the scan completes or the context ends.
+func (s *Scanner) Scan(ctx context.Context) (<-chan ScanResult, error) {
+	networks, err := s.networks()
+	if err != nil {
+		return nil, err
+	}
+
+	ports := s.config.Ports
+	if len(ports) == 0 {
+		ports = []int{defaultPort}
+	}
+	workers := s.config.Workers
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+
+	targets := make(chan ScanResult)
+	found := make(chan ScanResult)
+	results := make(chan ScanResult)
+
+	// Enumerate every host and port
+	go func() {
+		defer close(targets)
+		for _, network := range networks {
+			first, last, _ := hostRange(network)
+			for n := first; n <= last && n >= first; n++ {
+				ip := make(net.IP, 4)
+				binary.BigEndian.PutUint32(ip, n)
+				for _, port := range ports {
+					select {
+					case targets <- ScanResult{IPAddress: ip.String(), Port: port}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	report := func(result ScanResult) {
+		if !result.Valid {
+			return
+		}
+		select {
+		case found <- result:
+		case <-ctx.Done():
+		}
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for target := range targets {
+				s.scanHost(ctx, &target)
+				report(target)
+			}
+		}()
+	}
+
+	// Browse mDNS advertisements while the networks are scanned
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		browseCtx, cancel := context.WithTimeout(ctx, s.timeout())
+		defer cancel()
+
+		// A failed browse still leaves the scan results; anything can
+		// advertise _http._tcp, so fingerprint each device
+		candidates, _ := s.browser.Browse(browseCtx)
+		for _, candidate := range candidates {
+			s.identify(ctx, &candidate)
+			report(candidate)
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	// A device on several ports or also advertised is reported once
+	go func() {
+		defer close(results)
+		seen := make(map[string]bool)
+		for result := range found {
+			if seen[result.IPAddress] {
+				continue
+			}
+			seen[result.IPAddress] = true
+
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		}
+	}()
+
+	return results, nil
+}
+
+// timeout returns the time allowed to probe one host
+func (s *Scanner) timeout() time.Duration {
+	if s.config.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(s.config.Timeout) * time.Second
+}
+
+// networks returns the configured networks, or those of the local
+// interfaces if none are configured
+func (s *Scanner) networks() ([]*net.IPNet, error) {
+	if len(s.config.Networks) == 0 {
+		return interfaceNetworks()
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range s.config.Networks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		if _, _, err := hostRange(network); err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// interfaceNetworks returns the IPv4 networks of the local interfaces that
+// are small enough to scan
+func interfaceNetworks() ([]*net.IPNet, error) {
 	// Get all network interfaces
 	interfaces, err := net.Interfaces()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get network interfaces: %w", err)
 	}
 
-	var results []ScanResult
-
-	// Scan each interface
+	var networks []*net.IPNet
 	for _, iface := range interfaces {
 		// Skip loopback and down interfaces
 		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
@@ -53,7 +228,6 @@ func (s *Scanner) ScanNetwork(ctx context.Context) ([]ScanResult, error) {
 			continue
 		}
 
-		// Scan each address
 		for _, addr := range addresses {
 			// Skip non-IPv4 addresses
 			ipNet, ok := addr.(*net.IPNet)
@@ -66,112 +240,64 @@ func (s *Scanner) ScanNetwork(ctx context.Context) ([]ScanResult, error) {
 				continue
 			}
 
-			// Scan the network
-			networkResults, err := s.scanNetworkRange(ctx, ipNet)
-			if err != nil {
+			network := &net.IPNet{IP: ipNet.IP.To4().Mask(ipNet.Mask), Mask: ipNet.Mask}
+			if _, _, err := hostRange(network); err != nil {
 				continue
 			}
-
-			results = append(results, networkResults...)
+			networks = append(networks, network)
 		}
 	}
 
-	return results, nil
+	return networks, nil
 }
 
-// scanNetworkRange scans a network range for FluidNC devices
-func (s *Scanner) scanNetworkRange(ctx context.Context, ipNet *net.IPNet) ([]ScanResult, error) {
-	var results []ScanResult
-
-	// Get the network and broadcast addresses
-	network := ipNet.IP.Mask(ipNet.Mask)
-	broadcast := net.IP(make([]byte, 4))
-	for i := range broadcast {
-		broadcast[i] = network[i] | ^ipNet.Mask[i]
+// hostRange returns the first and last host address of an IPv4 network,
+// excluding the network and broadcast addresses where the prefix has them
+func hostRange(network *net.IPNet) (uint32, uint32, error) {
+	ip := network.IP.To4()
+	ones, bits := network.Mask.Size()
+	if ip == nil || bits != 32 {
+		return 0, 0, fmt.Errorf("network %s is not IPv4", network)
+	}
+	if ones < maxPrefixBits {
+		return 0, 0, fmt.Errorf("network %s is larger than a /%d", network, maxPrefixBits)
 	}
 
-	// Create a channel for results
-	resultChan := make(chan ScanResult, 256)
-
-	// Start scanning
-	for i := 1; i < 255; i++ {
-		// Check if context is cancelled
-		select {
-		case <-ctx.Done():
-			return results, ctx.Err()
-		default:
-		}
-
-		// Create IP address
-		ip := make(net.IP, 4)
-		copy(ip, network)
-		ip[3] = byte(i)
-
-		// Skip network and broadcast addresses
-		if ip.Equal(network) || ip.Equal(broadcast) {
-			continue
-		}
+	first := binary.BigEndian.Uint32(ip.Mask(network.Mask))
+	last := first | ^binary.BigEndian.Uint32(net.IP(network.Mask).To4())
 
-		// Start a goroutine to scan this IP
-		go s.scanIP(ctx, ip, resultChan)
+	// /31 and /32 have no network or broadcast address (RFC 3021)
+	if ones <= 30 {
+		first++
+		last--
 	}
+	return first, last, nil
+}
 
-	// Collect results
-	timeout := time.After(time.Duration(s.config.Timeout) * time.Second)
-	for i := 0; i < 254; i++ {
-		select {
-		case result := <-resultChan:
-			if result.Valid {
-				results = append(results, result)
-			}
-		case <-timeout:
-			return results, nil
-		case <-ctx.Done():
-			return results, ctx.Err()
-		}
+// ipLess orders dotted IPv4 addresses numerically
+func ipLess(a, b string) bool {
+	ipA, ipB := net.ParseIP(a).To4(), net.ParseIP(b).To4()
+	if ipA == nil || ipB == nil {
+		return a < b
 	}
-
-	return results, nil
+	return binary.BigEndian.Uint32(ipA) < binary.BigEndian.Uint32(ipB)
 }
 
-// scanIP scans a single IP address for FluidNC devices
-func (s *Scanner) scanIP(ctx context.Context, ip net.IP, resultChan chan<- ScanResult) {
+// scanHost probes a single host and port for a FluidNC device
+func (s *Scanner) scanHost(ctx context.Context, result *ScanResult) {
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.config.Timeout)*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, s.timeout())
 	defer cancel()
 
-	// Try to connect to the WebSocket port
-	address := net.JoinHostPort(ip.String(), strconv.Itoa(81))
-	conn, err := net.DialTimeout("tcp", address, time.Duration(s.config.Timeout)*time.Second)
+	// Check that something is listening before fingerprinting it
+	address := net.JoinHostPort(result.IPAddress, strconv.Itoa(result.Port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
-		resultChan <- ScanResult{
-			IPAddress: ip.String(),
-			Port:      81,
-			Valid:     false,
-			Error:     err,
-		}
+		result.Error = err
 		return
 	}
-	defer conn.Close()
-
-	// Set read deadline
-	conn.SetReadDeadline(time.Now().Add(time.Duration(s.config.Timeout) * time.Second))
-
-	// Try to validate if this is a FluidNC device
-	// This is a simple check - we could make it more sophisticated
-	valid := s.validateFluidNC(conn)
+	conn.Close()
 
-	resultChan <- ScanResult{
-		IPAddress: ip.String(),
-		Port:      81,
-		Valid:     valid,
-		Error:     nil,
-	}
+	s.identify(ctx, result)
 }
-
-// validateFluidNC validates if a connection is to a FluidNC device
-func (s *Scanner) validateFluidNC(conn net.Conn) bool {
-	// This is a simple validation - we could make it more sophisticated
-	// For now, we just check if the port is open
-	return true
-} 
\ No newline at end of file
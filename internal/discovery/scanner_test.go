@@ -0,0 +1,100 @@
+package discovery
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestHostRange tests host enumeration for different prefix lengths
+func TestHostRange(t *testing.T) {
+	tests := []struct {
+		cidr        string
+		first, last string
+		wantErr     bool
+	}{
+		{cidr: "192.168.1.0/24", first: "192.168.1.1", last: "192.168.1.254"},
+		{cidr: "10.0.4.0/22", first: "10.0.4.1", last: "10.0.7.254"},
+		{cidr: "192.168.1.8/30", first: "192.168.1.9", last: "192.168.1.10"},
+		{cidr: "192.168.1.8/31", first: "192.168.1.8", last: "192.168.1.9"},
+		{cidr: "192.168.1.7/32", first: "192.168.1.7", last: "192.168.1.7"},
+		{cidr: "10.0.0.0/8", wantErr: true},
+		{cidr: "fd00::/120", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			_, network, err := net.ParseCIDR(tt.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			first, last, err := hostRange(network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("hostRange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := ipString(first); got != tt.first {
+				t.Errorf("first = %s, want %s", got, tt.first)
+			}
+			if got := ipString(last); got != tt.last {
+				t.Errorf("last = %s, want %s", got, tt.last)
+			}
+		})
+	}
+}
+
+// ipString formats an address returned by hostRange
+func ipString(n uint32) string {
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String()
+}
+
+// TestScan tests that a configured range and port are scanned and the
+// device is reported once
+func TestScan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("FW version: FluidNC v3.7.8 (wifi) # hostname:router"))
+	}))
+	defer server.Close()
+
+	_, portStr, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	port, _ := strconv.Atoi(portStr)
+
+	s := NewScanner(types.DiscoveryConfig{
+		Timeout:  1,
+		Networks: []string{"127.0.0.1/32"},
+		Ports:    []int{port, port},
+		Workers:  2,
+	})
+	s.browser = &Browser{Group: startResponder(t, nil), Service: DefaultService}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := s.ScanNetwork(ctx)
+	if err != nil {
+		t.Fatalf("ScanNetwork() error = %v", err)
+	}
+	if len(results) != 1 || results[0].IPAddress != "127.0.0.1" || results[0].Hostname != "router" {
+		t.Errorf("ScanNetwork() = %+v", results)
+	}
+}
+
+// TestScanInvalidNetwork tests that a bad CIDR range is rejected
+func TestScanInvalidNetwork(t *testing.T) {
+	for _, cidr := range []string{"192.168.1.0", "10.0.0.0/8"} {
+		s := NewScanner(types.DiscoveryConfig{Networks: []string{cidr}})
+		if _, err := s.Scan(context.Background()); err == nil {
+			t.Errorf("Scan() with %q did not return error", cidr)
+		}
+	}
+}
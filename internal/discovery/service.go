@@ -0,0 +1,114 @@
+package discovery
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// defaultScanInterval is used when ScanInterval is not configured
+const defaultScanInterval = 5 * time.Minute
+
+// Service rescans the network on an interval and keeps a registry of the
+// controllers it finds
+type Service struct {
+	scanner  *Scanner
+	registry *Registry
+	interval time.Duration
+	changes  chan Change
+}
+
+// NewService creates a discovery service that records devices in registry
+func NewService(config types.DiscoveryConfig, registry *Registry) *Service {
+	interval := time.Duration(config.ScanInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultScanInterval
+	}
+
+	return &Service{
+		scanner:  NewScanner(config),
+		registry: registry,
+		interval: interval,
+		changes:  make(chan Change, 16),
+	}
+}
+
+// Changes returns a channel that receives a Change whenever a known device
+// moves to a new address. It is closed when Run returns.
+func (s *Service) Changes() <-chan Change {
+	return s.changes
+}
+
+// Registry returns the registry the service maintains
+func (s *Service) Registry() *Registry {
+	return s.registry
+}
+
+// Run scans immediately and then every interval until the context is done.
+// A failed scan is logged and retried at the next interval. Scan must not
+// be called once Run has returned.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer close(s.changes)
+
+	for {
+		if err := s.Scan(ctx); err != nil {
+			log.Printf("discovery scan failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan runs one scan, records what it finds and saves the registry
+func (s *Service) Scan(ctx context.Context) error {
+	found, err := s.scanner.Scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for result := range found {
+		count++
+		if s.stillAnswering(ctx, result) {
+			continue
+		}
+		change := s.registry.Update(result, time.Now())
+		if change == nil {
+			continue
+		}
+
+		log.Printf("FluidNC %s moved from %s to %s", change.Device.ID, change.OldAddress, change.Device.IPAddress)
+		select {
+		case s.changes <- *change:
+		default:
+			// Channel is full, skip this change
+		}
+	}
+	log.Printf("discovery scan found %d FluidNC devices", count)
+
+	return s.registry.Save()
+}
+
+// stillAnswering reports whether a known device found at a new address
+// also still identifies as itself at its old one. That is not a move, so
+// the old address is kept.
+func (s *Service) stillAnswering(ctx context.Context, result ScanResult) bool {
+	id := deviceID(result)
+	known, ok := s.registry.Device(id)
+	if !ok || known.IPAddress == result.IPAddress {
+		return false
+	}
+	if !s.scanner.answersAs(ctx, id, known.IPAddress, known.Port) {
+		return false
+	}
+	log.Printf("FluidNC %s answers at both %s and %s, keeping %s", id, known.IPAddress, result.IPAddress, known.IPAddress)
+	return true
+}
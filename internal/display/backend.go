@@ -0,0 +1,52 @@
+package display
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// Matrix backends selectable with DisplayConfig.Backend
+const (
+	BackendHardware = "hardware"
+	BackendPNG      = "png"
+	BackendTerminal = "terminal"
+)
+
+// NewMatrix creates the matrix selected by the configuration, or the
+// build's default backend if none is selected
+func NewMatrix(cfg *types.DisplayConfig) (types.Matrix, error) {
+	var (
+		matrix types.Matrix
+		err    error
+	)
+
+	backend := cfg.Backend
+	if backend == "" {
+		backend = defaultBackend
+	}
+
+	// Assign only on success so a failed constructor yields a nil interface
+	switch backend {
+	case BackendHardware:
+		matrix, err = newHardwareMatrix(cfg)
+	case BackendPNG:
+		var m *PNGMatrix
+		if m, err = NewPNGMatrix(cfg.Width, cfg.Height, cfg.Simulator); err == nil {
+			matrix = m
+		}
+	case BackendTerminal:
+		var m *TerminalMatrix
+		if m, err = NewTerminalMatrix(cfg.Width, cfg.Height, os.Stdout); err == nil {
+			matrix = m
+		}
+	default:
+		err = fmt.Errorf("unknown display backend %q", cfg.Backend)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return matrix, nil
+}
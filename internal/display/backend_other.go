@@ -0,0 +1,18 @@
+//go:build !rpi5
+
+package display
+
+import (
+	"errors"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// defaultBackend writes PNG frames, since this build cannot drive a panel
+const defaultBackend = BackendPNG
+
+// newHardwareMatrix fails in builds without the rpi5 tag, which leave out
+// the panel driver so the other backends build anywhere
+func newHardwareMatrix(cfg *types.DisplayConfig) (types.Matrix, error) {
+	return nil, errors.New("hardware display backend not built in, rebuild with -tags rpi5")
+}
@@ -0,0 +1,25 @@
+//go:build rpi5
+
+package display
+
+import (
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/rpi5matrix"
+)
+
+// defaultBackend drives the panel
+const defaultBackend = BackendHardware
+
+// newHardwareMatrix drives the panel through the Raspberry Pi 5 RP1
+func newHardwareMatrix(cfg *types.DisplayConfig) (types.Matrix, error) {
+	m, err := rpi5matrix.NewMatrix(&rpi5matrix.Config{
+		Width:      cfg.Width,
+		Height:     cfg.Height,
+		Brightness: cfg.Brightness,
+		GPIOPin:    cfg.GPIOPin,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,147 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// IconState names the icon of the machine state: alarm, door or spindle,
+// or none
+const IconState = "state"
+
+// stateIcons are the icons IconState chooses from
+var stateIcons = []string{"alarm", "door", "spindle"}
+
+var (
+	iconsMu sync.RWMutex
+	// icons are available to layouts by name. Alpha masks are drawn in the
+	// widget color, other images with their own colors.
+	icons = map[string]image.Image{
+		"alarm": bitmap(
+			"...#...",
+			"..###..",
+			".#####.",
+			".#####.",
+			".#####.",
+			"#######",
+			"...#...",
+		),
+		"spindle": bitmap(
+			"#######",
+			"..###..",
+			"..###..",
+			"...#...",
+			"..#.#..",
+			"...#...",
+			"..#.#..",
+		),
+		"door": bitmap(
+			"#####..",
+			"#...##.",
+			"#...#.#",
+			"#..##.#",
+			"#...#.#",
+			"#...##.",
+			"#####..",
+		),
+		"link": bitmap(
+			"..###..",
+			".#...#.",
+			"#.###.#",
+			".#...#.",
+			"...#...",
+			"..###..",
+			"...#...",
+		),
+	}
+)
+
+// RegisterIcon makes an image available to layouts under name, replacing
+// a built-in icon of the same name
+func RegisterIcon(name string, img image.Image) {
+	iconsMu.Lock()
+	defer iconsMu.Unlock()
+	icons[name] = img
+}
+
+// lookupIcon returns the icon registered under name
+func lookupIcon(name string) (image.Image, bool) {
+	iconsMu.RLock()
+	defer iconsMu.RUnlock()
+	img, ok := icons[name]
+	return img, ok
+}
+
+// stateIcon returns the name of the icon for the machine state, or "" if
+// there is none
+func stateIcon(data types.DisplayData) string {
+	status := data.MachineStatus
+	switch {
+	case status.State == types.StateAlarm:
+		return "alarm"
+	case status.State == types.StateDoor:
+		return "door"
+	case status.Accessories.SpindleCW || status.Accessories.SpindleCCW:
+		return "spindle"
+	}
+	return ""
+}
+
+// stateIconSize returns the size of the box holding any state icon
+func stateIconSize() image.Point {
+	var size image.Point
+	for _, name := range stateIcons {
+		if img, ok := lookupIcon(name); ok {
+			b := img.Bounds().Size()
+			if b.X > size.X {
+				size.X = b.X
+			}
+			if b.Y > size.Y {
+				size.Y = b.Y
+			}
+		}
+	}
+	return size
+}
+
+// bitmap converts rows of '#' for lit pixels into a mask
+func bitmap(rows ...string) *image.Alpha {
+	mask := image.NewAlpha(image.Rect(0, 0, len(rows[0]), len(rows)))
+	for y, row := range rows {
+		for x, px := range row {
+			if px == '#' {
+				mask.SetAlpha(x, y, color.Alpha{A: 0xff})
+			}
+		}
+	}
+	return mask
+}
+
+// drawIcon draws an icon with its top left corner at p, a mask in c
+func drawIcon(fb *framebuffer.FrameBuffer, p image.Point, img image.Image, c color.Color) {
+	mask, ok := img.(*image.Alpha)
+	if !ok {
+		fb.Composite(p, img, img.Bounds())
+		return
+	}
+
+	r, g, b, a := c.RGBA()
+	bounds := mask.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			px, py := p.X+x-bounds.Min.X, p.Y+y-bounds.Min.Y
+			switch cov := uint32(mask.AlphaAt(x, y).A); cov {
+			case 0:
+			case 0xff:
+				fb.Set(px, py, c)
+			default:
+				scale := func(v uint32) uint16 { return uint16(v * cov / 0xff) }
+				fb.Blend(px, py, color.RGBA64{R: scale(r), G: scale(g), B: scale(b), A: scale(a)})
+			}
+		}
+	}
+}
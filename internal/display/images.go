@@ -0,0 +1,45 @@
+package display
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/asset"
+)
+
+// defaultIconSize is the size of the built-in icons
+var defaultIconSize = image.Pt(7, 7)
+
+// LoadImage renders the image file of cfg at its configured size, or at
+// size if it has none
+func LoadImage(cfg types.ImageConfig, size image.Point) (*image.RGBA, error) {
+	if cfg.Width > 0 && cfg.Height > 0 {
+		size = image.Pt(cfg.Width, cfg.Height)
+	}
+	tint, err := parseColor(cfg.Tint, nil)
+	if err != nil || cfg.Tint == ColorState {
+		return nil, fmt.Errorf("invalid tint %q, want #rrggbb", cfg.Tint)
+	}
+	return asset.Load(cfg.Path, asset.Options{Size: size, Mode: cfg.Mode, Tint: tint})
+}
+
+// RegisterIcons loads every configured icon and registers it under its name
+// so layouts and the built-in layout can use it
+func RegisterIcons(configs []types.ImageConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("icon %s: name is required", cfg.Path)
+		}
+		img, err := LoadImage(cfg, defaultIconSize)
+		if err != nil {
+			return fmt.Errorf("icon %q: %w", cfg.Name, err)
+		}
+		if cfg.Color {
+			RegisterIcon(cfg.Name, img)
+		} else {
+			RegisterIcon(cfg.Name, asset.Mask(img))
+		}
+	}
+	return nil
+}
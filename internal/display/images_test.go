@@ -0,0 +1,63 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// TestRegisterIcons tests loading icons as masks drawn in the widget color
+// and as color images
+func TestRegisterIcons(t *testing.T) {
+	// A 14x14 blue square with a transparent border, scaled to 7x7
+	src := image.NewNRGBA(image.Rect(0, 0, 14, 14))
+	for y := 2; y < 12; y++ {
+		for x := 2; x < 12; x++ {
+			src.SetNRGBA(x, y, color.NRGBA{B: 255, A: 255})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "square.png")
+	file, _ := os.Create(path)
+	png.Encode(file, src)
+	file.Close()
+
+	err := RegisterIcons([]types.ImageConfig{
+		{Name: "test-mask", Path: path},
+		{Name: "test-color", Path: path, Color: true},
+	})
+	if err != nil {
+		t.Fatalf("RegisterIcons() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want color.RGBA
+	}{
+		{"test-mask", red},
+		{"test-color", color.RGBA{B: 255, A: 255}},
+	}
+	for _, tt := range tests {
+		screen := Screen{Widgets: []Widget{{Type: WidgetIcon, Icon: tt.name, Color: "#ff0000"}}}
+		if err := screen.Validate(16, 16); err != nil {
+			t.Fatalf("%s: Validate() error = %v", tt.name, err)
+		}
+		fb := framebuffer.New(16, 16)
+		screen.Draw(fb, types.DisplayData{})
+		if got := fb.RGBAt(3, 3); got != tt.want {
+			t.Errorf("%s: center pixel = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if err := RegisterIcons([]types.ImageConfig{{Path: path}}); err == nil {
+		t.Errorf("RegisterIcons() without a name error = nil")
+	}
+	if err := RegisterIcons([]types.ImageConfig{{Name: "x", Path: path, Tint: "blue"}}); err == nil {
+		t.Errorf("RegisterIcons() with an invalid tint error = nil")
+	}
+}
@@ -0,0 +1,459 @@
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+	"gopkg.in/yaml.v3"
+)
+
+// Widget types
+const (
+	WidgetText  = "text"
+	WidgetField = "field"
+	WidgetBar   = "bar"
+	WidgetIcon  = "icon"
+	WidgetRect  = "rect"
+)
+
+// ColorState colors a widget by the machine state
+const ColorState = "state"
+
+// fieldAliases are short names for the first element of a field path
+var fieldAliases = map[string]string{
+	"status": "MachineStatus",
+}
+
+// fieldValues lists every value of the field types limited to a fixed set,
+// so that widgets bound to them are validated with the widest
+var fieldValues = map[reflect.Type][]interface{}{
+	reflect.TypeOf(types.MachineState("")): {
+		types.StateIdle, types.StateRun, types.StateHold, types.StateJog, types.StateAlarm,
+		types.StateDoor, types.StateCheck, types.StateHome, types.StateSleep, types.StateUnknown,
+	},
+	reflect.TypeOf(types.ConnectionState("")): {
+		types.ConnectionDisconnected, types.ConnectionConnecting, types.ConnectionConnected,
+		types.ConnectionLost, types.ConnectionGivingUp,
+	},
+	reflect.TypeOf(false): {false, true},
+}
+
+// Screen is a set of widgets drawn together. With several screens each is
+// a page shown in turn, unless a page is pinned by the machine state.
+type Screen struct {
+	Name    string   `json:"name" yaml:"name"`
+	Widgets []Widget `json:"widgets" yaml:"widgets"`
+	// Duration is the time the page is shown in rotation, in seconds
+	Duration float64 `json:"duration" yaml:"duration"`
+	// States pin the page while the machine is in one of them; Unknown
+	// matches a disconnected machine
+	States []types.MachineState `json:"states" yaml:"states"`
+	// Hidden leaves the page out of rotation, so it is only shown pinned
+	Hidden bool `json:"hidden" yaml:"hidden"`
+	// Transition is how the page replaces the previous one: none (the
+	// default), slide or fade
+	Transition string `json:"transition" yaml:"transition"`
+	// TransitionTime is the length of the transition in seconds
+	TransitionTime float64 `json:"transition_time" yaml:"transition_time"`
+}
+
+// Widget is one element of a screen. Its box is placed so that its anchor
+// point lies at the same anchor point of the panel, moved by X and Y.
+type Widget struct {
+	Type string `json:"type" yaml:"type"`
+	X    int    `json:"x" yaml:"x"`
+	Y    int    `json:"y" yaml:"y"`
+	// Width and Height size the box; text is measured when they are 0
+	Width  int `json:"width" yaml:"width"`
+	Height int `json:"height" yaml:"height"`
+	// Anchor is one of top-left (the default), top, top-right, left,
+	// center, right, bottom-left, bottom or bottom-right
+	Anchor string `json:"anchor" yaml:"anchor"`
+	// Align places text in its box: left (the default), center or right
+	Align string `json:"align" yaml:"align"`
+	// Color is "#rrggbb" or "state", white if empty
+	Color      string `json:"color" yaml:"color"`
+	Background string `json:"background" yaml:"background"`
+	// Text is the content of a text widget
+	Text string `json:"text" yaml:"text"`
+	// Field is the path of a DisplayData value, e.g. status.coordinates.x
+	Field string `json:"field" yaml:"field"`
+	// Format is the fmt verb used for a field, "%v" if empty
+	Format string `json:"format" yaml:"format"`
+	// Font names a built-in or configured font for text, 5x7 if empty
+	Font string `json:"font" yaml:"font"`
+	// Min and Max are the range of a bar
+	Min float64 `json:"min" yaml:"min"`
+	Max float64 `json:"max" yaml:"max"`
+	// Icon names a built-in or configured icon, or "state" for the icon
+	// of the machine state
+	Icon string `json:"icon" yaml:"icon"`
+	// Fill fills a rect instead of outlining it
+	Fill bool `json:"fill" yaml:"fill"`
+
+	field      []int
+	face       *font.Face
+	color      color.Color
+	background color.Color
+	// clipped is set once text that does not fit has been logged
+	clipped bool
+}
+
+// LoadScreens loads screen definitions from a JSON or, by its extension,
+// YAML file and validates them against a panel of the given size
+func LoadScreens(path string, width, height int) ([]Screen, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read layout: %w", err)
+	}
+	var file struct {
+		Screens []Screen `json:"screens" yaml:"screens"`
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	default:
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse layout %s: %w", path, err)
+	}
+	if len(file.Screens) == 0 {
+		return nil, fmt.Errorf("layout %s: no screens defined", path)
+	}
+
+	for i := range file.Screens {
+		if err := file.Screens[i].Validate(width, height); err != nil {
+			return nil, err
+		}
+	}
+	return file.Screens, nil
+}
+
+// Validate checks every widget and that it fits on a panel of the given
+// size, and prepares the screen for drawing
+func (s *Screen) Validate(width, height int) error {
+	if err := s.validatePage(); err != nil {
+		return fmt.Errorf("screen %q: %w", s.Name, err)
+	}
+	panel := image.Rect(0, 0, width, height)
+	for i := range s.Widgets {
+		w := &s.Widgets[i]
+		if err := w.validate(panel); err != nil {
+			return fmt.Errorf("screen %q widget %d (%s): %w", s.Name, i, w.Type, err)
+		}
+	}
+	return nil
+}
+
+// Draw draws every widget of the screen with the values from data
+func (s *Screen) Draw(fb *framebuffer.FrameBuffer, data types.DisplayData) {
+	for i := range s.Widgets {
+		s.Widgets[i].draw(fb, data)
+	}
+}
+
+// validate checks the widget and resolves its field and colors
+func (w *Widget) validate(panel image.Rectangle) error {
+	var err error
+	if w.color, err = parseColor(w.Color, color.White); err != nil {
+		return err
+	}
+	w.face = textFace
+	if w.Font != "" {
+		if w.face, err = font.Lookup(w.Font); err != nil {
+			return err
+		}
+	}
+	if w.background, err = parseColor(w.Background, nil); err != nil {
+		return err
+	}
+	if _, _, ok := anchorPoint(w.Anchor); !ok {
+		return fmt.Errorf("unknown anchor %q", w.Anchor)
+	}
+	switch w.Align {
+	case "", "left", "center", "right":
+	default:
+		return fmt.Errorf("unknown align %q", w.Align)
+	}
+
+	switch w.Type {
+	case WidgetText, WidgetRect:
+	case WidgetField, WidgetBar:
+		var kind reflect.Kind
+		if w.field, kind, err = resolveField(w.Field); err != nil {
+			return err
+		}
+		if w.Type == WidgetBar && !isNumeric(kind) {
+			return fmt.Errorf("field %q is not a number", w.Field)
+		}
+		if w.Type == WidgetBar && w.Max <= w.Min {
+			return fmt.Errorf("max %v must be greater than min %v", w.Max, w.Min)
+		}
+	case WidgetIcon:
+		if _, ok := lookupIcon(w.Icon); !ok && w.Icon != IconState {
+			return fmt.Errorf("unknown icon %q", w.Icon)
+		}
+	default:
+		return fmt.Errorf("unknown widget type")
+	}
+
+	// Fields are measured with their widest value if the type has a fixed
+	// set of them, otherwise with the zero value, which may format as
+	// nothing; text that outgrows its box at runtime is logged by draw
+	size := w.size(w.widestText())
+	if w.Type == WidgetField && size.X == 0 {
+		size.X = w.face.Measure("0")
+	}
+	if size.X <= 0 || size.Y <= 0 {
+		return fmt.Errorf("width and height are required")
+	}
+	if box := w.box(panel, size); !box.In(panel) {
+		return fmt.Errorf("%v is outside the %dx%d panel", box, panel.Dx(), panel.Dy())
+	}
+	return nil
+}
+
+// draw draws the widget
+func (w *Widget) draw(fb *framebuffer.FrameBuffer, data types.DisplayData) {
+	c := w.color
+	if w.Color == ColorState {
+		c = stateColor(data.MachineStatus.State)
+	}
+
+	background := w.background
+	if w.Background == ColorState {
+		background = stateColor(data.MachineStatus.State)
+	}
+
+	text := w.text(data)
+	box := w.box(fb.Bounds(), w.size(text))
+	if background != nil {
+		fillRect(fb, box, background)
+	}
+
+	switch w.Type {
+	case WidgetText, WidgetField:
+		x := box.Min.X
+		switch w.Align {
+		case "center":
+			x += (box.Dx() - w.face.Measure(text)) / 2
+		case "right":
+			x = box.Max.X - w.face.Measure(text)
+		}
+		w.face.Draw(fb.SubImage(box), x, box.Min.Y, text, c)
+		if !w.clipped && (w.face.Measure(text) > box.Dx() || !box.In(fb.Bounds())) {
+			w.clipped = true
+			log.Printf("Layout %s widget text %q is clipped", w.Type, text)
+		}
+
+	case WidgetBar:
+		v := toFloat(w.value(data))
+		frac := (v - w.Min) / (w.Max - w.Min)
+		if frac < 0 {
+			frac = 0
+		} else if frac > 1 {
+			frac = 1
+		}
+		filled := box
+		filled.Max.X = box.Min.X + int(frac*float64(box.Dx())+0.5)
+		fillRect(fb, filled, c)
+
+	case WidgetIcon:
+		name := w.Icon
+		if name == IconState {
+			name = stateIcon(data)
+		}
+		if img, ok := lookupIcon(name); ok {
+			drawIcon(fb, box.Min, img, c)
+		}
+
+	case WidgetRect:
+		if w.Fill {
+			fillRect(fb, box, c)
+		} else {
+			drawRect(fb, box, c)
+		}
+	}
+}
+
+// text returns the text drawn by a text or field widget
+func (w *Widget) text(data types.DisplayData) string {
+	switch w.Type {
+	case WidgetText:
+		return w.Text
+	case WidgetField:
+		return w.format(w.value(data))
+	}
+	return ""
+}
+
+// widestText returns the text of the widget with the widest value of its
+// field's type, or with the zero value if the type has no fixed set
+func (w *Widget) widestText() string {
+	text := w.text(types.DisplayData{})
+	if w.Type != WidgetField {
+		return text
+	}
+	field := reflect.TypeOf(types.DisplayData{}).FieldByIndex(w.field)
+	for _, v := range fieldValues[field.Type] {
+		if s := w.format(v); w.face.Measure(s) > w.face.Measure(text) {
+			text = s
+		}
+	}
+	return text
+}
+
+// format formats a field value with the widget's format
+func (w *Widget) format(value interface{}) string {
+	format := w.Format
+	if format == "" {
+		format = "%v"
+	}
+	return fmt.Sprintf(format, value)
+}
+
+// value returns the bound field of data
+func (w *Widget) value(data types.DisplayData) interface{} {
+	return reflect.ValueOf(data).FieldByIndex(w.field).Interface()
+}
+
+// size returns the size of the widget's box
+func (w *Widget) size(text string) image.Point {
+	size := image.Pt(w.Width, w.Height)
+	switch w.Type {
+	case WidgetText, WidgetField:
+		if size.X == 0 {
+			size.X = w.face.Measure(text)
+		}
+		if size.Y == 0 {
+			size.Y = w.face.Height()
+		}
+	case WidgetIcon:
+		if w.Icon == IconState {
+			size = stateIconSize()
+		} else if img, ok := lookupIcon(w.Icon); ok {
+			size = img.Bounds().Size()
+		}
+	}
+	return size
+}
+
+// box places a box of the given size on the panel
+func (w *Widget) box(panel image.Rectangle, size image.Point) image.Rectangle {
+	ax, ay, _ := anchorPoint(w.Anchor)
+	min := image.Pt(
+		panel.Min.X+(panel.Dx()-size.X)*ax/2+w.X,
+		panel.Min.Y+(panel.Dy()-size.Y)*ay/2+w.Y,
+	)
+	return image.Rectangle{Min: min, Max: min.Add(size)}
+}
+
+// anchorPoint returns the horizontal and vertical position of an anchor
+// in halves of the panel
+func anchorPoint(anchor string) (x, y int, ok bool) {
+	switch anchor {
+	case "", "top-left":
+		return 0, 0, true
+	case "top":
+		return 1, 0, true
+	case "top-right":
+		return 2, 0, true
+	case "left":
+		return 0, 1, true
+	case "center":
+		return 1, 1, true
+	case "right":
+		return 2, 1, true
+	case "bottom-left":
+		return 0, 2, true
+	case "bottom":
+		return 1, 2, true
+	case "bottom-right":
+		return 2, 2, true
+	}
+	return 0, 0, false
+}
+
+// resolveField returns the index path and kind of a DisplayData field
+// given as dot-separated, case-insensitive names
+func resolveField(path string) ([]int, reflect.Kind, error) {
+	if path == "" {
+		return nil, reflect.Invalid, fmt.Errorf("field is required")
+	}
+
+	t := reflect.TypeOf(types.DisplayData{})
+	var index []int
+	for i, name := range strings.Split(path, ".") {
+		if alias, ok := fieldAliases[name]; ok && i == 0 {
+			name = alias
+		}
+		name = strings.ReplaceAll(name, "_", "")
+
+		if t.Kind() != reflect.Struct {
+			return nil, reflect.Invalid, fmt.Errorf("field %q: %s has no fields", path, t)
+		}
+		f, ok := t.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+		if !ok || !f.IsExported() {
+			return nil, reflect.Invalid, fmt.Errorf("field %q: unknown name %q", path, name)
+		}
+		index = append(index, f.Index...)
+		t = f.Type
+	}
+	return index, t.Kind(), nil
+}
+
+// isNumeric reports whether values of kind can be shown on a bar
+func isNumeric(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toFloat converts a numeric value to float64
+func toFloat(v interface{}) float64 {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int())
+	case rv.CanUint():
+		return float64(rv.Uint())
+	case rv.CanFloat():
+		return rv.Float()
+	}
+	return 0
+}
+
+// parseColor parses "#rrggbb", returning def for an empty string and nil
+// for "state", which is resolved when drawing
+func parseColor(s string, def color.Color) (color.Color, error) {
+	switch s {
+	case "":
+		return def, nil
+	case ColorState:
+		return nil, nil
+	}
+	if len(s) != 7 || s[0] != '#' {
+		return nil, fmt.Errorf("invalid color %q, want #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q, want #rrggbb", s)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
@@ -0,0 +1,151 @@
+package display
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// TestLoadScreens tests loading the example layout
+func TestLoadScreens(t *testing.T) {
+	screens, err := LoadScreens(filepath.Join("..", "..", "examples", "layout.json"), 64, 32)
+	if err != nil {
+		t.Fatalf("LoadScreens() error = %v", err)
+	}
+	var names []string
+	for _, s := range screens {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, ","); got != "dro,job,network,alarm" {
+		t.Errorf("LoadScreens() pages = %s", got)
+	}
+
+	// The YAML example is the same layout
+	fromYAML, err := LoadScreens(filepath.Join("..", "..", "examples", "layout.yaml"), 64, 32)
+	if err != nil {
+		t.Fatalf("LoadScreens() YAML error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, screens) {
+		t.Errorf("LoadScreens() YAML = %+v, want %+v", fromYAML, screens)
+	}
+
+	path := filepath.Join(t.TempDir(), "layout.yml")
+	os.WriteFile(path, []byte("screens: []"), 0o644)
+	if _, err := LoadScreens(path, 64, 32); err == nil || !strings.Contains(err.Error(), "no screens") {
+		t.Errorf("LoadScreens(%s) error = %v, want no screens", path, err)
+	}
+}
+
+// TestScreenValidate tests that invalid widgets are rejected
+func TestScreenValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		widget Widget
+		want   string
+	}{
+		{"text", Widget{Type: WidgetText, Text: "Idle"}, ""},
+		{"anchored", Widget{Type: WidgetText, Text: "Idle", Anchor: "bottom-right"}, ""},
+		{"off panel", Widget{Type: WidgetText, Text: "Idle", Y: 30}, "outside"},
+		{"too wide", Widget{Type: WidgetText, Text: "Hello, world!"}, "outside"},
+		{"unknown type", Widget{Type: "clock"}, "unknown widget type"},
+		{"unknown anchor", Widget{Type: WidgetText, Text: "a", Anchor: "middle"}, "unknown anchor"},
+		{"bad color", Widget{Type: WidgetText, Text: "a", Color: "red"}, "invalid color"},
+		{"unknown field", Widget{Type: WidgetField, Field: "status.speed"}, "unknown name"},
+		{"state field", Widget{Type: WidgetField, Field: "status.state", Font: "4x6"}, ""},
+		{"widest state", Widget{Type: WidgetField, Field: "status.state"}, "outside"},
+		{"text bar", Widget{Type: WidgetBar, Field: "name", Max: 1, Width: 8, Height: 1}, "not a number"},
+		{"empty bar", Widget{Type: WidgetBar, Field: "job.percent", Max: 100}, "width and height"},
+		{"unknown icon", Widget{Type: WidgetIcon, Icon: "rocket"}, "unknown icon"},
+		{"state icon", Widget{Type: WidgetIcon, Icon: IconState, Anchor: "bottom-right"}, ""},
+		{"small font", Widget{Type: WidgetText, Text: "Running!", Font: "4x6"}, ""},
+		{"large font", Widget{Type: WidgetText, Text: "Running!"}, "outside"},
+		{"unknown font", Widget{Type: WidgetText, Text: "a", Font: "9x15"}, "unknown font"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screen := Screen{Name: "test", Widgets: []Widget{tt.widget}}
+			err := screen.Validate(32, 16)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestScreenDraw tests that widgets are placed and bound to data
+func TestScreenDraw(t *testing.T) {
+	screen := Screen{Widgets: []Widget{
+		{Type: WidgetField, Field: "status.work_coordinates.x", Format: "%.0f", Anchor: "top-right", Color: "#ff0000"},
+		{Type: WidgetBar, Field: "job.percent", Max: 100, Anchor: "bottom", Width: 10, Height: 1, Color: "#00ff00"},
+		{Type: WidgetRect, X: 1, Y: 1, Width: 3, Height: 3, Fill: true, Color: "state"},
+	}}
+	if err := screen.Validate(32, 16); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	data := types.DisplayData{
+		MachineStatus: types.MachineStatus{
+			State:           types.StateAlarm,
+			WorkCoordinates: types.Coordinates{X: 1},
+		},
+		Job: types.JobStatus{Percent: 50},
+	}
+	fb := framebuffer.New(32, 16)
+	screen.Draw(fb, data)
+
+	tests := []struct {
+		name  string
+		rect  image.Rectangle
+		color color.RGBA
+		want  int
+	}{
+		// '1' is a vertical line in its third column
+		{"right aligned field", image.Rect(29, 0, 30, 7), color.RGBA{R: 255, A: 255}, 7},
+		{"bar half filled", image.Rect(11, 15, 21, 16), color.RGBA{G: 255, A: 255}, 5},
+		{"state colored rect", image.Rect(1, 1, 4, 4), color.RGBA{R: 255, A: 255}, 9},
+	}
+	for _, tt := range tests {
+		if got := countColor(fb, tt.rect, tt.color); got != tt.want {
+			t.Errorf("%s: %d pixels, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestScreenDrawClipped tests that text outgrowing its box is logged once
+func TestScreenDrawClipped(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	screen := Screen{Widgets: []Widget{{Type: WidgetField, Field: "message", Width: 32}}}
+	if err := screen.Validate(32, 16); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	fb := framebuffer.New(32, 16)
+	screen.Draw(fb, types.DisplayData{Message: "fits"})
+	if buf.Len() != 0 {
+		t.Errorf("log = %q, want nothing", buf.String())
+	}
+	for i := 0; i < 2; i++ {
+		screen.Draw(fb, types.DisplayData{Message: "Reset to continue"})
+	}
+	if n := strings.Count(buf.String(), "clipped"); n != 1 {
+		t.Errorf("log = %q, want one clipped line", buf.String())
+	}
+}
@@ -0,0 +1,131 @@
+package display
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+const (
+	// ViewRotate shows one machine at a time, rotating between them
+	ViewRotate = "rotate"
+	// ViewSummary shows every machine side by side
+	ViewSummary = "summary"
+)
+
+// defaultRotateInterval is used when RotateInterval is not configured
+const defaultRotateInterval = 5 * time.Second
+
+// NeedsAttention reports whether a machine is in a state the operator
+// must see, i.e. Alarm or Door
+func NeedsAttention(data types.DisplayData) bool {
+	state := data.MachineStatus.State
+	return state == types.StateAlarm || state == types.StateDoor
+}
+
+// MachineSelector chooses the machine shown in rotate view
+type MachineSelector struct {
+	// Interval is the time each machine is shown
+	Interval time.Duration
+	index    int
+	shownAt  time.Time
+}
+
+// Select returns the index of the machine to show. A machine that needs
+// attention is pinned until it recovers; otherwise the selection advances
+// every Interval.
+func (s *MachineSelector) Select(machines []types.DisplayData, now time.Time) int {
+	if len(machines) == 0 {
+		return -1
+	}
+
+	// Stay on the current machine while it needs attention, otherwise jump
+	// to the first one that does. Once it recovers it is shown for another
+	// full interval.
+	if s.index < len(machines) && NeedsAttention(machines[s.index]) {
+		s.shownAt = now
+		return s.index
+	}
+	for i, m := range machines {
+		if NeedsAttention(m) {
+			s.index = i
+			s.shownAt = now
+			return i
+		}
+	}
+
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultRotateInterval
+	}
+
+	if s.index >= len(machines) {
+		s.index = 0
+		s.shownAt = now
+	}
+	if s.shownAt.IsZero() {
+		s.shownAt = now
+	}
+	if now.Sub(s.shownAt) >= interval {
+		s.index = (s.index + 1) % len(machines)
+		s.shownAt = now
+	}
+	return s.index
+}
+
+// SummaryColumnLayout represents one machine in the side-by-side summary
+type SummaryColumnLayout struct {
+	X         int
+	Y         int
+	Width     int
+	Height    int
+	Name      string
+	State     types.MachineState
+	Highlight bool
+	Color     color.Color
+}
+
+// GetSummaryLayout splits the panel into one column per machine
+func (r *Renderer) GetSummaryLayout(machines []types.DisplayData) []SummaryColumnLayout {
+	if len(machines) == 0 {
+		return nil
+	}
+
+	width := r.cfg.Width / len(machines)
+	columns := make([]SummaryColumnLayout, len(machines))
+	for i, m := range machines {
+		state := m.MachineStatus.State
+		if !m.Connected {
+			state = types.StateUnknown
+		}
+
+		columns[i] = SummaryColumnLayout{
+			X:         i * width,
+			Y:         0,
+			Width:     width,
+			Height:    r.cfg.Height,
+			Name:      m.Name,
+			State:     state,
+			Highlight: m.Connected && NeedsAttention(m),
+			Color:     stateColor(state),
+		}
+	}
+	return columns
+}
+
+// stateColor returns the color used for a machine state
+func stateColor(state types.MachineState) color.Color {
+	switch state {
+	case types.StateAlarm:
+		return color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	case types.StateDoor, types.StateHold:
+		return color.RGBA{R: 255, G: 140, B: 0, A: 255}
+	case types.StateRun, types.StateJog, types.StateHome:
+		return color.RGBA{R: 0, G: 255, B: 0, A: 255}
+	case types.StateUnknown, "":
+		return color.RGBA{R: 64, G: 64, B: 64, A: 255}
+	default:
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+}
@@ -0,0 +1,93 @@
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// machineData returns display data for a named machine in the given state
+func machineData(name string, state types.MachineState) types.DisplayData {
+	return types.DisplayData{
+		Name:          name,
+		Connected:     true,
+		MachineStatus: types.MachineStatus{State: state},
+	}
+}
+
+// TestMachineSelectorRotates tests rotation between machines
+func TestMachineSelectorRotates(t *testing.T) {
+	machines := []types.DisplayData{
+		machineData("router", types.StateIdle),
+		machineData("laser", types.StateRun),
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := MachineSelector{Interval: 5 * time.Second}
+
+	steps := []struct {
+		offset time.Duration
+		want   int
+	}{
+		{0, 0},
+		{4 * time.Second, 0},
+		{5 * time.Second, 1},
+		{10 * time.Second, 0},
+	}
+	for _, step := range steps {
+		if got := s.Select(machines, start.Add(step.offset)); got != step.want {
+			t.Errorf("Select() at %v = %d, want %d", step.offset, got, step.want)
+		}
+	}
+}
+
+// TestMachineSelectorPinsAlarm tests that a machine in Alarm is pinned
+func TestMachineSelectorPinsAlarm(t *testing.T) {
+	machines := []types.DisplayData{
+		machineData("router", types.StateIdle),
+		machineData("laser", types.StateAlarm),
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := MachineSelector{Interval: time.Second}
+
+	for i := 0; i < 5; i++ {
+		if got := s.Select(machines, start.Add(time.Duration(i)*time.Second)); got != 1 {
+			t.Fatalf("Select() = %d, want the alarmed machine", got)
+		}
+	}
+
+	machines[1].MachineStatus.State = types.StateIdle
+	if got := s.Select(machines, start.Add(4500*time.Millisecond)); got != 1 {
+		t.Errorf("Select() after recovery = %d, want 1 until the interval passes", got)
+	}
+	if got := s.Select(machines, start.Add(5500*time.Millisecond)); got != 0 {
+		t.Errorf("Select() after interval = %d, want 0", got)
+	}
+}
+
+// TestGetSummaryLayout tests the side-by-side summary columns
+func TestGetSummaryLayout(t *testing.T) {
+	r := NewRenderer(&types.DisplayConfig{Width: 64, Height: 32})
+	machines := []types.DisplayData{
+		machineData("router", types.StateRun),
+		machineData("laser", types.StateDoor),
+	}
+	machines[0].Connected = false
+
+	columns := r.GetSummaryLayout(machines)
+	if len(columns) != 2 {
+		t.Fatalf("len(columns) = %d, want 2", len(columns))
+	}
+	if columns[1].X != 32 || columns[1].Width != 32 || !columns[1].Highlight {
+		t.Errorf("columns[1] = %+v", columns[1])
+	}
+	if columns[0].State != types.StateUnknown || columns[0].Highlight {
+		t.Errorf("columns[0] = %+v, want disconnected", columns[0])
+	}
+
+	// An alarm left over from before the connection dropped is not highlighted
+	machines[0].MachineStatus.State = types.StateAlarm
+	if columns := r.GetSummaryLayout(machines); columns[0].Highlight {
+		t.Errorf("columns[0] = %+v, want disconnected without highlight", columns[0])
+	}
+}
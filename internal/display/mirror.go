@@ -0,0 +1,142 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// Mirror is a matrix that passes everything through to another matrix and
+// publishes a copy of every shown frame, e.g. to a browser preview
+type Mirror struct {
+	matrix      types.Matrix
+	fb          *framebuffer.FrameBuffer
+	last        *framebuffer.FrameBuffer
+	subscribers map[chan *framebuffer.FrameBuffer]struct{}
+	taps        []func(*framebuffer.FrameBuffer)
+	mu          sync.Mutex
+}
+
+// NewMirror wraps matrix, which may be nil to only publish frames
+func NewMirror(matrix types.Matrix, width, height int) *Mirror {
+	return &Mirror{
+		matrix:      matrix,
+		fb:          framebuffer.New(width, height),
+		last:        framebuffer.New(width, height),
+		subscribers: make(map[chan *framebuffer.FrameBuffer]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving every shown frame and a function
+// that cancels the subscription. A slow subscriber only misses frames.
+func (m *Mirror) Subscribe() (<-chan *framebuffer.FrameBuffer, func()) {
+	ch := make(chan *framebuffer.FrameBuffer, 1)
+
+	m.mu.Lock()
+	m.subscribers[ch] = struct{}{}
+	m.mu.Unlock()
+
+	return ch, func() {
+		m.mu.Lock()
+		delete(m.subscribers, ch)
+		m.mu.Unlock()
+	}
+}
+
+// Tap calls fn with every shown frame before Show returns, e.g. to record
+// them. fn must not modify the frame or call back into the mirror.
+func (m *Mirror) Tap(fn func(*framebuffer.FrameBuffer)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.taps = append(m.taps, fn)
+}
+
+// Last returns the most recently shown frame
+func (m *Mirror) Last() *framebuffer.FrameBuffer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// Clear clears the matrix
+func (m *Mirror) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Clear()
+	if m.matrix != nil {
+		return m.matrix.Clear()
+	}
+	return nil
+}
+
+// SetPixel sets a pixel at the given coordinates to the given color
+func (m *Mirror) SetPixel(x, y int, c color.Color) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !(image.Point{x, y}.In(m.fb.Bounds())) {
+		return fmt.Errorf("coordinates out of bounds: (%d, %d)", x, y)
+	}
+	m.fb.Set(x, y, c)
+	if m.matrix != nil {
+		return m.matrix.SetPixel(x, y, c)
+	}
+	return nil
+}
+
+// Show shows the current buffer and publishes it
+func (m *Mirror) Show() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.publish()
+	if m.matrix != nil {
+		return m.matrix.Show()
+	}
+	return nil
+}
+
+// Present shows a whole frame and publishes it
+func (m *Mirror) Present(fb *framebuffer.FrameBuffer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Blit(image.Point{}, fb, fb.Bounds())
+	m.publish()
+	if m.matrix != nil {
+		return m.matrix.Present(fb)
+	}
+	return nil
+}
+
+// Close closes the wrapped matrix
+func (m *Mirror) Close() error {
+	if m.matrix != nil {
+		return m.matrix.Close()
+	}
+	return nil
+}
+
+// publish sends a copy of the buffer to every subscriber, replacing any
+// frame they have not taken yet
+func (m *Mirror) publish() {
+	m.last = m.fb.Clone()
+	m.fb.ResetDirty()
+
+	for _, fn := range m.taps {
+		fn(m.last)
+	}
+
+	for ch := range m.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- m.last
+	}
+}
@@ -0,0 +1,226 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// Page transitions
+const (
+	TransitionNone  = "none"
+	TransitionSlide = "slide"
+	TransitionFade  = "fade"
+)
+
+// defaultTransitionTime is used when a transition has no TransitionTime
+const defaultTransitionTime = 400 * time.Millisecond
+
+// frameInterval is the time between frames while a transition runs
+const frameInterval = time.Second / 30
+
+// validatePage checks the rotation and transition settings of a screen
+func (s *Screen) validatePage() error {
+	if s.Duration < 0 || s.TransitionTime < 0 {
+		return fmt.Errorf("duration and transition time must not be negative")
+	}
+	switch s.Transition {
+	case "", TransitionNone, TransitionSlide, TransitionFade:
+	default:
+		return fmt.Errorf("unknown transition %q", s.Transition)
+	}
+	for _, state := range s.States {
+		switch state {
+		case types.StateIdle, types.StateRun, types.StateHold, types.StateJog,
+			types.StateAlarm, types.StateDoor, types.StateCheck, types.StateHome,
+			types.StateSleep, types.StateUnknown:
+		default:
+			return fmt.Errorf("unknown state %q", state)
+		}
+	}
+	return nil
+}
+
+// duration returns the time the page is shown in rotation
+func (s *Screen) duration() time.Duration {
+	if s.Duration <= 0 {
+		return defaultRotateInterval
+	}
+	return time.Duration(s.Duration * float64(time.Second))
+}
+
+// transitionTime returns the length of the transition to the page, 0 if
+// it replaces the previous page at once
+func (s *Screen) transitionTime() time.Duration {
+	switch {
+	case s.Transition == "" || s.Transition == TransitionNone:
+		return 0
+	case s.TransitionTime <= 0:
+		return defaultTransitionTime
+	}
+	return time.Duration(s.TransitionTime * float64(time.Second))
+}
+
+// pinned reports whether the machine state pins the page
+func (s *Screen) pinned(data types.DisplayData) bool {
+	state := data.MachineStatus.State
+	if !data.Connected {
+		state = types.StateUnknown
+	}
+	for _, pin := range s.States {
+		if pin == state {
+			return true
+		}
+	}
+	return false
+}
+
+// PageSelector chooses the page shown from a set of screens
+type PageSelector struct {
+	index   int
+	shownAt time.Time
+}
+
+// Select returns the index of the page to show. The first page pinned by
+// the machine state is shown while the state lasts; otherwise the pages
+// that are not hidden rotate, each shown for its duration.
+func (s *PageSelector) Select(pages []Screen, data types.DisplayData, now time.Time) int {
+	if len(pages) == 0 {
+		return -1
+	}
+
+	// A page that was pinned is shown for another full duration once the
+	// state changes, unless it is hidden
+	for i := range pages {
+		if pages[i].pinned(data) {
+			s.index = i
+			s.shownAt = now
+			return i
+		}
+	}
+	if s.index >= len(pages) || pages[s.index].Hidden {
+		s.index = nextPage(pages, s.index)
+		s.shownAt = now
+	}
+
+	if s.shownAt.IsZero() {
+		s.shownAt = now
+	}
+	if now.Sub(s.shownAt) >= pages[s.index].duration() {
+		s.index = nextPage(pages, s.index)
+		s.shownAt = now
+	}
+	return s.index
+}
+
+// nextPage returns the first page after i that is not hidden, or 0 if all
+// of them are
+func nextPage(pages []Screen, i int) int {
+	for n := 1; n <= len(pages); n++ {
+		if j := (i + n) % len(pages); !pages[j].Hidden {
+			return j
+		}
+	}
+	return 0
+}
+
+// pageTransition is a change of page in progress
+type pageTransition struct {
+	from  int
+	start time.Time
+}
+
+// pageState is the page rotation of one machine
+type pageState struct {
+	pages PageSelector
+	// page is the index last drawn, -1 before the first
+	page       int
+	transition *pageTransition
+}
+
+// drawPages draws the selected page of the machine, animating the change
+// from the page shown before. Both pages are drawn with the current data
+// while the transition runs. Each machine rotates through the pages on its
+// own, and switching machines changes the page at once.
+func (r *Renderer) drawPages(fb *framebuffer.FrameBuffer, data types.DisplayData, now time.Time) {
+	state := r.pageStates[data.Name]
+	if state == nil {
+		state = &pageState{page: -1}
+		r.pageStates[data.Name] = state
+	}
+	if data.Name != r.pageMachine {
+		state.transition = nil
+	}
+
+	index := state.pages.Select(r.screens, data, now)
+	if index != state.page {
+		state.transition = nil
+		if state.page >= 0 && data.Name == r.pageMachine && r.screens[index].transitionTime() > 0 {
+			state.transition = &pageTransition{from: state.page, start: now}
+		}
+		state.page = index
+	}
+	r.pageMachine = data.Name
+
+	page := &r.screens[index]
+	if state.transition == nil {
+		page.Draw(fb, data)
+		return
+	}
+	progress := float64(now.Sub(state.transition.start)) / float64(page.transitionTime())
+	if progress >= 1 {
+		state.transition = nil
+		page.Draw(fb, data)
+		return
+	}
+
+	from, to := r.pageBuffer(0, fb.Bounds().Size()), r.pageBuffer(1, fb.Bounds().Size())
+	r.screens[state.transition.from].Draw(from, data)
+	page.Draw(to, data)
+	drawTransition(fb, from, to, page.Transition, ease(progress))
+}
+
+// pageBuffer returns the i-th cleared offscreen buffer for drawing pages
+func (r *Renderer) pageBuffer(i int, size image.Point) *framebuffer.FrameBuffer {
+	fb := r.pageBuffers[i]
+	if fb == nil || fb.Bounds().Size() != size {
+		fb = framebuffer.New(size.X, size.Y)
+		r.pageBuffers[i] = fb
+	}
+	fb.Clear()
+	return fb
+}
+
+// drawTransition draws the state of a transition from one page to another
+// at progress p between 0 and 1; the pages are the size of dst with their
+// origin at its top left corner
+func drawTransition(dst, from, to *framebuffer.FrameBuffer, kind string, p float64) {
+	b := dst.Bounds()
+	switch kind {
+	case TransitionSlide:
+		// The new page pushes the old one out to the left
+		offset := int(p*float64(b.Dx()) + 0.5)
+		dst.Blit(b.Min.Sub(image.Pt(offset, 0)), from, from.Bounds())
+		dst.Blit(b.Min.Add(image.Pt(b.Dx()-offset, 0)), to, to.Bounds())
+	case TransitionFade:
+		mix := func(a, b uint8) uint8 {
+			return uint8(float64(a)*(1-p) + float64(b)*p + 0.5)
+		}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				c0, c1 := from.RGBAt(x-b.Min.X, y-b.Min.Y), to.RGBAt(x-b.Min.X, y-b.Min.Y)
+				dst.SetRGB(x, y, mix(c0.R, c1.R), mix(c0.G, c1.G), mix(c0.B, c1.B))
+			}
+		}
+	default:
+		dst.Blit(b.Min, to, to.Bounds())
+	}
+}
+
+// ease smooths the start and end of a transition
+func ease(p float64) float64 {
+	return p * p * (3 - 2*p)
+}
@@ -0,0 +1,203 @@
+package display
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// TestPageSelector tests rotating pages and pinning them by state
+func TestPageSelector(t *testing.T) {
+	pages := []Screen{
+		{Name: "dro", Duration: 2, States: []types.MachineState{types.StateJog}},
+		{Name: "job", States: []types.MachineState{types.StateRun}},
+		{Name: "network", Duration: 3, States: []types.MachineState{types.StateUnknown}},
+		{Name: "alarm", Hidden: true, States: []types.MachineState{types.StateAlarm}},
+	}
+
+	start := time.Now()
+	steps := []struct {
+		at        time.Duration
+		state     types.MachineState
+		connected bool
+		want      string
+	}{
+		{0, types.StateIdle, true, "dro"},
+		{time.Second, types.StateIdle, true, "dro"},
+		{2 * time.Second, types.StateIdle, true, "job"},
+		{3 * time.Second, types.StateAlarm, true, "alarm"},
+		// A hidden page gives way as soon as it is no longer pinned
+		{4 * time.Second, types.StateIdle, true, "dro"},
+		{5 * time.Second, types.StateRun, true, "job"},
+		{6 * time.Second, types.StateJog, true, "dro"},
+		// A pinned page is shown for a full duration afterwards
+		{7 * time.Second, types.StateIdle, true, "dro"},
+		{9 * time.Second, types.StateIdle, true, "job"},
+		{14 * time.Second, types.StateIdle, true, "network"},
+		{15 * time.Second, types.StateIdle, false, "network"},
+		{20 * time.Second, types.StateIdle, false, "network"},
+		{21 * time.Second, types.StateIdle, true, "network"},
+		{23 * time.Second, types.StateIdle, true, "dro"},
+	}
+
+	var s PageSelector
+	for _, step := range steps {
+		data := machineData("router", step.state)
+		data.Connected = step.connected
+		got := pages[s.Select(pages, data, start.Add(step.at))].Name
+		if got != step.want {
+			t.Errorf("%v %s: Select() = %s, want %s", step.at, step.state, got, step.want)
+		}
+	}
+}
+
+// TestScreenValidatePage tests that invalid page settings are rejected
+func TestScreenValidatePage(t *testing.T) {
+	tests := []struct {
+		name   string
+		screen Screen
+		want   string
+	}{
+		{"valid", Screen{Duration: 5, Transition: TransitionSlide, States: []types.MachineState{types.StateRun}}, ""},
+		{"negative duration", Screen{Duration: -1}, "negative"},
+		{"unknown transition", Screen{Transition: "wipe"}, "unknown transition"},
+		{"unknown state", Screen{States: []types.MachineState{"Running"}}, "unknown state"},
+	}
+	for _, tt := range tests {
+		err := tt.screen.Validate(64, 32)
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: Validate() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Validate() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// TestDrawTransition tests the frames halfway through each transition
+func TestDrawTransition(t *testing.T) {
+	from, to := framebuffer.New(4, 1), framebuffer.New(4, 1)
+	from.Fill(red)
+	to.Fill(green)
+
+	tests := []struct {
+		kind string
+		want []color.RGBA
+	}{
+		{TransitionSlide, []color.RGBA{red, red, green, green}},
+		{TransitionFade, []color.RGBA{{R: 128, G: 128, A: 255}, {R: 128, G: 128, A: 255}, {R: 128, G: 128, A: 255}, {R: 128, G: 128, A: 255}}},
+		{TransitionNone, []color.RGBA{green, green, green, green}},
+	}
+	for _, tt := range tests {
+		dst := framebuffer.New(4, 1)
+		drawTransition(dst, from, to, tt.kind, 0.5)
+		for x, want := range tt.want {
+			if got := dst.RGBAt(x, 0); got != want {
+				t.Errorf("%s: pixel %d = %v, want %v", tt.kind, x, got, want)
+			}
+		}
+	}
+}
+
+// TestRendererPages tests that the renderer animates the change of page
+// and stops animating when the transition ends
+func TestRendererPages(t *testing.T) {
+	r, mirror, _ := newTestRenderer("")
+	screens := []Screen{
+		{Name: "red", Widgets: []Widget{{Type: WidgetRect, Width: 64, Height: 32, Fill: true, Color: "#ff0000"}}},
+		{Name: "green", Transition: TransitionFade, TransitionTime: 1,
+			Widgets: []Widget{{Type: WidgetRect, Width: 64, Height: 32, Fill: true, Color: "#00ff00"}}},
+	}
+	for i := range screens {
+		if err := screens[i].Validate(64, 32); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	}
+	r.SetScreens(screens)
+
+	start := time.Now()
+	data := machineData("router", types.StateIdle)
+	data.MachineStatus.LastUpdated = start
+	r.Update(data)
+
+	steps := []struct {
+		at        time.Duration
+		animating bool
+		want      color.RGBA
+	}{
+		{0, false, red},
+		{defaultRotateInterval, true, red},
+		{defaultRotateInterval + 500*time.Millisecond, true, color.RGBA{R: 128, G: 128, A: 255}},
+		{defaultRotateInterval + time.Second, false, green},
+	}
+	for _, step := range steps {
+		r.now = func() time.Time { return start.Add(step.at) }
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+		if got := r.animating(); got != step.animating {
+			t.Errorf("%v: animating() = %v, want %v", step.at, got, step.animating)
+		}
+		if got := mirror.Last().RGBAt(10, 10); got != step.want {
+			t.Errorf("%v: pixel = %v, want %v", step.at, got, step.want)
+		}
+	}
+}
+
+// TestRendererPagesPerMachine tests that each machine in rotate view keeps
+// its own place in the pages, without animating the switch of machine
+func TestRendererPagesPerMachine(t *testing.T) {
+	r, mirror, _ := newTestRenderer(ViewRotate)
+	screens := []Screen{
+		{Name: "red", Duration: 2,
+			Widgets: []Widget{{Type: WidgetRect, Width: 64, Height: 32, Fill: true, Color: "#ff0000"}}},
+		{Name: "green", Duration: 10, Transition: TransitionFade, TransitionTime: 1,
+			Widgets: []Widget{{Type: WidgetRect, Width: 64, Height: 32, Fill: true, Color: "#00ff00"}}},
+	}
+	for i := range screens {
+		if err := screens[i].Validate(64, 32); err != nil {
+			t.Fatalf("Validate() error = %v", err)
+		}
+	}
+	r.SetScreens(screens)
+
+	start := time.Now()
+	router, laser := machineData("router", types.StateIdle), machineData("laser", types.StateIdle)
+	router.MachineStatus.LastUpdated = start
+	laser.MachineStatus.LastUpdated = start
+	r.Update(router, laser)
+
+	steps := []struct {
+		at        time.Duration
+		animating bool
+		want      color.RGBA
+	}{
+		{0, false, red},
+		{2 * time.Second, true, red},
+		{3 * time.Second, false, green},
+		// The laser starts on its first page rather than the router's
+		{defaultRotateInterval, false, red},
+		{defaultRotateInterval + 2*time.Second, true, red},
+		// The router is back on the page it was left on
+		{2 * defaultRotateInterval, false, green},
+	}
+	for _, step := range steps {
+		r.now = func() time.Time { return start.Add(step.at) }
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+		if got := r.animating(); got != step.animating {
+			t.Errorf("%v: animating() = %v, want %v", step.at, got, step.animating)
+		}
+		if got := mirror.Last().RGBAt(10, 10); got != step.want {
+			t.Errorf("%v: pixel = %v, want %v", step.at, got, step.want)
+		}
+	}
+}
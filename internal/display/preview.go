@@ -0,0 +1,157 @@
+package display
+
+import (
+	"encoding/binary"
+	"html/template"
+	"image/png"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+	"github.com/gorilla/websocket"
+)
+
+// previewWriteWait is the time allowed to send one frame to a browser
+const previewWriteWait = 5 * time.Second
+
+// previewPage draws frames received over the WebSocket as LED dots. Each
+// binary message is a 4 byte big-endian width and height followed by the
+// RGB pixels row by row.
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>FluidNC LED preview</title>
+<style>
+body { background: #111; color: #888; font-family: sans-serif; text-align: center; }
+canvas { margin-top: 2em; background: #000; }
+</style>
+</head>
+<body>
+<canvas id="panel"></canvas>
+<p id="status">connecting…</p>
+<script>
+const pitch = {{.Pitch}};
+const canvas = document.getElementById("panel");
+const ctx = canvas.getContext("2d");
+const status = document.getElementById("status");
+
+function draw(buf) {
+  const view = new DataView(buf);
+  const w = view.getUint16(0), h = view.getUint16(2);
+  const px = new Uint8Array(buf, 4);
+  canvas.width = w * pitch;
+  canvas.height = h * pitch;
+  ctx.fillStyle = "#000";
+  ctx.fillRect(0, 0, canvas.width, canvas.height);
+  for (let y = 0; y < h; y++) {
+    for (let x = 0; x < w; x++) {
+      const i = 3 * (y * w + x);
+      ctx.fillStyle = "rgb(" + px[i] + "," + px[i + 1] + "," + px[i + 2] + ")";
+      ctx.beginPath();
+      ctx.arc((x + 0.5) * pitch, (y + 0.5) * pitch, pitch * 0.4, 0, 2 * Math.PI);
+      ctx.fill();
+    }
+  }
+}
+
+function connect() {
+  const scheme = location.protocol === "https:" ? "wss://" : "ws://";
+  const ws = new WebSocket(scheme + location.host + location.pathname.replace(/\/$/, "") + "/ws");
+  ws.binaryType = "arraybuffer";
+  ws.onopen = () => { status.textContent = "live"; };
+  ws.onmessage = (e) => draw(e.data);
+  ws.onclose = () => {
+    status.textContent = "disconnected, retrying…";
+    setTimeout(connect, 1000);
+  };
+}
+connect();
+</script>
+</body>
+</html>
+`))
+
+// PreviewHandler serves a live browser preview of the frames shown on a
+// mirror: the page at /, frames over a WebSocket at /ws and the current
+// frame as an LED-rendered PNG at /frame.png
+func PreviewHandler(mirror *Mirror, pitch int) http.Handler {
+	if pitch < 1 {
+		pitch = 10
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		previewPage.Execute(w, struct{ Pitch int }{pitch})
+	})
+
+	mux.HandleFunc("/frame.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Cache-Control", "no-store")
+		png.Encode(w, RenderLEDs(mirror.Last(), pitch, true))
+	})
+
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		var upgrader websocket.Upgrader
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		streamFrames(conn, mirror)
+	})
+
+	return mux
+}
+
+// streamFrames sends the current frame and then every new one until the
+// browser goes away
+func streamFrames(conn *websocket.Conn, mirror *Mirror) {
+	defer conn.Close()
+
+	frames, cancel := mirror.Subscribe()
+	defer cancel()
+
+	// Reading is needed to process close and ping frames
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	fb := mirror.Last()
+	for {
+		conn.SetWriteDeadline(time.Now().Add(previewWriteWait))
+		if err := conn.WriteMessage(websocket.BinaryMessage, encodeFrame(fb)); err != nil {
+			log.Printf("preview client gone: %v", err)
+			return
+		}
+
+		select {
+		case fb = <-frames:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// encodeFrame packs a frame as width, height and RGB pixels
+func encodeFrame(fb *framebuffer.FrameBuffer) []byte {
+	w, h := fb.Width(), fb.Height()
+	data := make([]byte, 4, 4+3*w*h)
+	binary.BigEndian.PutUint16(data[0:], uint16(w))
+	binary.BigEndian.PutUint16(data[2:], uint16(h))
+	for y := fb.Rect.Min.Y; y < fb.Rect.Max.Y; y++ {
+		data = append(data, fb.Pix[fb.PixOffset(fb.Rect.Min.X, y):fb.PixOffset(fb.Rect.Max.X, y)]...)
+	}
+	return data
+}
@@ -0,0 +1,55 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestPreviewHandler tests the page and live frames over the WebSocket
+func TestPreviewHandler(t *testing.T) {
+	mirror := NewMirror(nil, 2, 1)
+	server := httptest.NewServer(PreviewHandler(mirror, 8))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Contains(page, []byte("<canvas")) || !regexp.MustCompile(`const pitch = +8 *;`).Match(page) {
+		t.Errorf("page = %s", page)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// The current frame is sent on connect
+	if _, data, err := conn.ReadMessage(); err != nil || !bytes.Equal(data, []byte{0, 2, 0, 1, 0, 0, 0, 0, 0, 0}) {
+		t.Fatalf("first frame = %v, %v", data, err)
+	}
+
+	mirror.SetPixel(1, 0, color.RGBA{R: 255, G: 128, B: 1, A: 255})
+	mirror.Show()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if want := []byte{0, 2, 0, 1, 0, 0, 0, 255, 128, 1}; !bytes.Equal(data, want) {
+		t.Errorf("frame = %v, want %v", data, want)
+	}
+}
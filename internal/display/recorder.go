@@ -0,0 +1,220 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// GIF frame timing in hundredths of a second
+const (
+	minGIFDelay     = 2
+	defaultGIFDelay = 50
+)
+
+// RecordedFrame is a frame shown on the panel and when it was shown
+type RecordedFrame struct {
+	Frame *framebuffer.FrameBuffer
+	At    time.Time
+}
+
+// Recorder keeps the most recently shown frames in a ring buffer so a
+// session can be exported as an animated GIF
+type Recorder struct {
+	frames []RecordedFrame
+	next   int
+	full   bool
+	now    func() time.Time
+	mu     sync.Mutex
+}
+
+// NewRecorder creates a recorder keeping up to capacity frames
+func NewRecorder(capacity int) *Recorder {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Recorder{
+		frames: make([]RecordedFrame, capacity),
+		now:    time.Now,
+	}
+}
+
+// Record stores a frame, dropping the oldest once the buffer is full. It
+// can be passed to Mirror.Tap; frames must not be modified afterwards.
+func (r *Recorder) Record(fb *framebuffer.FrameBuffer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.frames[r.next] = RecordedFrame{Frame: fb, At: r.now()}
+	r.next = (r.next + 1) % len(r.frames)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Frames returns the recorded frames shown between from and to, oldest
+// first. A zero from or to leaves that end of the range open.
+func (r *Recorder) Frames(from, to time.Time) []RecordedFrame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ordered := r.frames[:r.next]
+	if r.full {
+		ordered = append(append([]RecordedFrame(nil), r.frames[r.next:]...), r.frames[:r.next]...)
+	}
+
+	var frames []RecordedFrame
+	for _, f := range ordered {
+		if (!from.IsZero() && f.At.Before(from)) || (!to.IsZero() && f.At.After(to)) {
+			continue
+		}
+		frames = append(frames, f)
+	}
+	return frames
+}
+
+// WriteGIF encodes the frames shown between from and to as an animated GIF
+// with each LED pitch pixels wide
+func (r *Recorder) WriteGIF(w io.Writer, from, to time.Time, pitch int) error {
+	return EncodeGIF(w, r.Frames(from, to), pitch)
+}
+
+// WriteFile writes the whole recording to path as an animated GIF with
+// each LED pitch pixels wide
+func (r *Recorder) WriteFile(path string, pitch int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteGIF(f, time.Time{}, time.Time{}, pitch); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// EncodeGIF encodes frames as an animated GIF played back at the speed
+// they were recorded. Identical consecutive frames are merged.
+func EncodeGIF(w io.Writer, frames []RecordedFrame, pitch int) error {
+	if len(frames) == 0 {
+		return fmt.Errorf("no frames to encode")
+	}
+
+	anim := &gif.GIF{}
+	pal, exact := framePalette(frames)
+	var prev *framebuffer.FrameBuffer
+	delay := defaultGIFDelay
+	for i, f := range frames {
+		// The last frame is held as long as the interval before it
+		if i+1 < len(frames) {
+			delay = int(frames[i+1].At.Sub(f.At) / (10 * time.Millisecond))
+		}
+		if delay < minGIFDelay {
+			delay = minGIFDelay
+		}
+
+		if prev != nil && sameFrame(prev, f.Frame) {
+			anim.Delay[len(anim.Delay)-1] += delay
+			continue
+		}
+		prev = f.Frame
+
+		img := RenderLEDs(f.Frame, pitch, true)
+		paletted := image.NewPaletted(img.Bounds(), pal)
+		if exact {
+			draw.Draw(paletted, img.Bounds(), img, image.Point{}, draw.Src)
+		} else {
+			draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.Point{})
+		}
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
+
+// framePalette returns the exact colors used by the frames when they fit
+// in a GIF palette, or a general palette to dither against otherwise
+func framePalette(frames []RecordedFrame) (color.Palette, bool) {
+	seen := map[color.RGBA]bool{{A: 0xff}: true}
+	pal := color.Palette{color.RGBA{A: 0xff}}
+	for _, f := range frames {
+		fb := f.Frame
+		for y := fb.Rect.Min.Y; y < fb.Rect.Max.Y; y++ {
+			for x := fb.Rect.Min.X; x < fb.Rect.Max.X; x++ {
+				c := fb.RGBAt(x, y)
+				if seen[c] {
+					continue
+				}
+				if len(pal) == 256 {
+					return palette.Plan9, false
+				}
+				seen[c] = true
+				pal = append(pal, c)
+			}
+		}
+	}
+	return pal, true
+}
+
+// sameFrame reports whether two frames have the same pixels
+func sameFrame(a, b *framebuffer.FrameBuffer) bool {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return false
+	}
+	for y := 0; y < a.Height(); y++ {
+		for x := 0; x < a.Width(); x++ {
+			if a.RGBAt(a.Rect.Min.X+x, a.Rect.Min.Y+y) != b.RGBAt(b.Rect.Min.X+x, b.Rect.Min.Y+y) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Handler serves the recording as a GIF. The range is given by the query
+// parameters from and to (RFC 3339), or last (a duration such as 30s).
+func (r *Recorder) Handler(pitch int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var from, to time.Time
+		query := req.URL.Query()
+		if last := query.Get("last"); last != "" {
+			d, err := time.ParseDuration(last)
+			if err != nil {
+				http.Error(w, "invalid last: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			from = r.now().Add(-d)
+		}
+		for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+			if v := query.Get(name); v != "" {
+				parsed, err := time.Parse(time.RFC3339, v)
+				if err != nil {
+					http.Error(w, "invalid "+name+": "+err.Error(), http.StatusBadRequest)
+					return
+				}
+				*t = parsed
+			}
+		}
+
+		frames := r.Frames(from, to)
+		if len(frames) == 0 {
+			http.Error(w, "no frames recorded in range", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/gif")
+		if err := EncodeGIF(w, frames, pitch); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
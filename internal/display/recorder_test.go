@@ -0,0 +1,138 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// solidFrame returns a 2x1 frame filled with c
+func solidFrame(c color.Color) *framebuffer.FrameBuffer {
+	fb := framebuffer.New(2, 1)
+	fb.Fill(c)
+	return fb
+}
+
+// recordAt returns a recorder whose clock is advanced by step per frame
+func recordAt(capacity int, start time.Time, step time.Duration, frames ...*framebuffer.FrameBuffer) *Recorder {
+	r := NewRecorder(capacity)
+	now := start
+	r.now = func() time.Time { return now }
+	for _, fb := range frames {
+		r.Record(fb)
+		now = now.Add(step)
+	}
+	return r
+}
+
+// TestRecorderRing tests that the oldest frames are dropped and ranges apply
+func TestRecorderRing(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var frames []*framebuffer.FrameBuffer
+	for i := 0; i < 5; i++ {
+		frames = append(frames, solidFrame(color.Gray{Y: uint8(i)}))
+	}
+	r := recordAt(3, start, time.Second, frames...)
+
+	got := r.Frames(time.Time{}, time.Time{})
+	if len(got) != 3 || got[0].Frame != frames[2] || got[2].Frame != frames[4] {
+		t.Fatalf("Frames() = %+v, want the last three frames", got)
+	}
+
+	got = r.Frames(start.Add(3*time.Second), start.Add(3*time.Second))
+	if len(got) != 1 || got[0].Frame != frames[3] {
+		t.Errorf("Frames() in range = %+v, want frame 3", got)
+	}
+}
+
+// TestEncodeGIF tests frame timing, merging of repeated frames and colors
+func TestEncodeGIF(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	green := color.RGBA{G: 255, A: 255}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := recordAt(10, start, 500*time.Millisecond, solidFrame(red), solidFrame(red), solidFrame(green))
+
+	var buf bytes.Buffer
+	if err := r.WriteGIF(&buf, time.Time{}, time.Time{}, 1); err != nil {
+		t.Fatalf("WriteGIF() error = %v", err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+
+	if len(anim.Image) != 2 {
+		t.Fatalf("len(Image) = %d, want repeated frames merged into 2", len(anim.Image))
+	}
+	if anim.Delay[0] != 100 || anim.Delay[1] != 50 {
+		t.Errorf("Delay = %v, want [100 50]", anim.Delay)
+	}
+	if got := color.RGBAModel.Convert(anim.Image[1].At(0, 0)); got != green {
+		t.Errorf("frame 1 color = %v, want %v", got, green)
+	}
+}
+
+// TestRecorderWriteFile tests writing the whole recording to a file
+func TestRecorderWriteFile(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := recordAt(10, start, time.Second, solidFrame(color.Black), solidFrame(color.White))
+
+	path := filepath.Join(t.TempDir(), "session.gif")
+	if err := r.WriteFile(path, 3); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		t.Fatalf("gif.DecodeAll() error = %v", err)
+	}
+	if len(anim.Image) != 2 || anim.Config.Width != 6 || anim.Config.Height != 3 {
+		t.Errorf("GIF has %d frames of %dx%d, want 2 of 6x3", len(anim.Image), anim.Config.Width, anim.Config.Height)
+	}
+
+	if err := r.WriteFile(filepath.Join(t.TempDir(), "missing", "session.gif"), 1); err == nil {
+		t.Error("WriteFile() into a missing directory did not return error")
+	}
+}
+
+// TestRecorderHandler tests exporting the last part of a session over HTTP
+func TestRecorderHandler(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := recordAt(10, start, time.Second, solidFrame(color.White), solidFrame(color.Black))
+	r.now = func() time.Time { return start.Add(90 * time.Second) }
+
+	server := httptest.NewServer(r.Handler(1))
+	defer server.Close()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"?last=2m", http.StatusOK},
+		{"?last=10s", http.StatusNotFound},
+		{"?from=yesterday", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(server.URL + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s status = %d, want %d", tt.query, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -3,26 +3,67 @@ package display
 import (
 	"context"
 	"fmt"
+	"image"
 	"image/color"
 	"log"
 	"sync"
 	"time"
 
-	"github.com/fkcurrie/fluidnc-led-golang/internal/config"
 	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
 )
 
-// Renderer handles the display rendering logic
+const (
+	// defaultUpdateInterval is used when UpdateInterval is not configured
+	defaultUpdateInterval = 500 * time.Millisecond
+	// splashDuration is how long the controller summary stays up once known
+	splashDuration = 3 * time.Second
+)
+
+// textFace is the font of the built-in layout
+var textFace = font.Default()
+
+// lineHeight is the distance between lines of text
+var lineHeight = textFace.LineHeight
+
+// Renderer draws the monitored machines onto the matrix
 type Renderer struct {
-	cfg    *config.DisplayConfig
-	matrix types.Matrix
-	mu     sync.RWMutex
+	cfg      *types.DisplayConfig
+	matrix   types.Matrix
+	machines []types.DisplayData
+	selector MachineSelector
+	// screens replace the built-in layout when set, one page at a time
+	screens []Screen
+	// pageStates holds the page rotation of each machine by name, and
+	// pageMachine the machine pages were last drawn for
+	pageStates  map[string]*pageState
+	pageMachine string
+	pageBuffers [2]*framebuffer.FrameBuffer
+	// splash is shown at startup until the controller is known
+	splash image.Image
+	// splashUntil holds, per machine name, when the controller summary
+	// was first seen plus splashDuration
+	splashUntil map[string]time.Time
+	// shown is the frame last presented, next the one being drawn
+	shown   *framebuffer.FrameBuffer
+	next    *framebuffer.FrameBuffer
+	updated chan struct{}
+	now     func() time.Time
+	mu      sync.RWMutex
 }
 
 // NewRenderer creates a new renderer instance
-func NewRenderer(cfg *config.DisplayConfig) *Renderer {
+func NewRenderer(cfg *types.DisplayConfig) *Renderer {
 	return &Renderer{
 		cfg: cfg,
+		selector: MachineSelector{
+			Interval: time.Duration(cfg.RotateInterval * float64(time.Second)),
+		},
+		pageStates:  make(map[string]*pageState),
+		splashUntil: make(map[string]time.Time),
+		updated:     make(chan struct{}, 1),
+		now:         time.Now,
 	}
 }
 
@@ -31,44 +72,252 @@ func (r *Renderer) SetMatrix(matrix types.Matrix) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.matrix = matrix
+	r.shown = nil
+}
+
+// SetScreens draws machines with validated screen definitions instead of
+// the built-in layout; nil restores it. Several screens are pages shown in
+// rotation or pinned by the machine state.
+func (r *Renderer) SetScreens(screens []Screen) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.screens = screens
+	r.pageStates = make(map[string]*pageState)
+	r.pageMachine = ""
+}
+
+// SetSplash shows img at startup, before the controller has identified
+// itself; nil shows the controller summary straight away
+func (r *Renderer) SetSplash(img image.Image) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.splash = img
+}
+
+// Update replaces the data of the monitored machines and renders it
+// without waiting for the next tick
+func (r *Renderer) Update(machines ...types.DisplayData) {
+	r.mu.Lock()
+	r.machines = append(r.machines[:0], machines...)
+	r.mu.Unlock()
+
+	select {
+	case r.updated <- struct{}{}:
+	default:
+	}
 }
 
-// Start starts the renderer
+// Start renders on every update and on a ticker, so rotation and timeouts
+// take effect without new data. Page transitions are rendered at a higher
+// frame rate while they run.
 func (r *Renderer) Start(ctx context.Context) error {
-	ticker := time.NewTicker(time.Duration(r.cfg.RefreshRate) * time.Millisecond)
+	interval := time.Duration(r.cfg.UpdateInterval * float64(time.Second))
+	if interval <= 0 {
+		interval = defaultUpdateInterval
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
+		if err := r.render(); err != nil {
+			log.Printf("Failed to render: %v", err)
+		}
+
+		var frame <-chan time.Time
+		if r.animating() {
+			frame = time.After(frameInterval)
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			if err := r.render(); err != nil {
-				log.Printf("Failed to render: %v", err)
-			}
+		case <-r.updated:
+		case <-frame:
 		}
 	}
 }
 
-// render renders the current state to the matrix
-func (r *Renderer) render() error {
+// animating reports whether a page transition is running
+func (r *Renderer) animating() bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
+	state := r.pageStates[r.pageMachine]
+	return state != nil && state.transition != nil
+}
+
+// render draws the current state and presents it if it changed
+func (r *Renderer) render() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
 	if r.matrix == nil {
 		return nil
 	}
 
-	// TODO: Implement actual rendering logic
-	// For now, just clear the matrix
-	return r.matrix.Clear()
+	if r.next == nil {
+		r.next = framebuffer.New(r.cfg.Width, r.cfg.Height)
+	}
+	r.next.Clear()
+	r.draw(r.next, r.now())
+
+	// The frame is redrawn from scratch, so only the pixels that differ
+	// from the one shown are dirty
+	if r.shown != nil {
+		changed := changedRect(r.next, r.shown)
+		if changed.Empty() {
+			return nil
+		}
+		r.next.ResetDirty()
+		r.next.MarkDirty(changed)
+	}
+	if err := r.matrix.Present(r.next); err != nil {
+		return err
+	}
+	r.shown, r.next = r.next, r.shown
+	return nil
 }
 
-// GetDisplayLayout returns the layout for the display
+// changedRect returns the bounds of the pixels that differ between a and
+// b, all of a if their sizes differ
+func changedRect(a, b *framebuffer.FrameBuffer) image.Rectangle {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return a.Bounds()
+	}
+	var changed image.Rectangle
+	for y := 0; y < a.Height(); y++ {
+		for x := 0; x < a.Width(); x++ {
+			if a.RGBAt(a.Rect.Min.X+x, a.Rect.Min.Y+y) != b.RGBAt(b.Rect.Min.X+x, b.Rect.Min.Y+y) {
+				p := a.Rect.Min.Add(image.Pt(x, y))
+				changed = changed.Union(image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))})
+			}
+		}
+	}
+	return changed
+}
+
+// draw draws the machine chosen by the configured view, or all of them
+func (r *Renderer) draw(fb *framebuffer.FrameBuffer, now time.Time) {
+	switch {
+	case len(r.machines) == 0:
+		r.drawMachine(fb, types.DisplayData{}, now)
+	case len(r.machines) > 1 && r.cfg.MachineView == ViewSummary:
+		for _, column := range r.GetSummaryLayout(r.machines) {
+			drawSummaryColumn(fb, column)
+		}
+	default:
+		r.drawMachine(fb, r.machines[r.selector.Select(r.machines, now)], now)
+	}
+}
+
+// drawMachine draws one machine using the configured screens, or the
+// display layout if there are none
+func (r *Renderer) drawMachine(fb *framebuffer.FrameBuffer, data types.DisplayData, now time.Time) {
+	if _, ok := r.splashUntil[data.Name]; !ok && len(data.Controller.Summary()) > 0 {
+		r.splashUntil[data.Name] = now.Add(splashDuration)
+	}
+
+	layout := r.GetDisplayLayout(data)
+	if len(r.screens) > 0 && !layout.Splash.Visible {
+		r.drawPages(fb, data, now)
+		return
+	}
+
+	indicator := layout.ConnectionIndicator
+	fillRect(fb, image.Rect(indicator.X, indicator.Y, indicator.X+2, indicator.Y+2), indicator.Color)
+
+	if layout.Splash.Visible {
+		if r.splash != nil && len(data.Controller.Summary()) == 0 {
+			fb.Composite(image.Point{}, r.splash, r.splash.Bounds())
+			return
+		}
+		for i, line := range layout.Splash.Lines {
+			textFace.Draw(fb, layout.Splash.X, layout.Splash.Y+i*lineHeight, line, layout.Splash.Color)
+		}
+		return
+	}
+
+	// The address is more useful than a stale state while disconnected
+	if data.Connected {
+		textFace.Draw(fb, layout.Status.X, layout.Status.Y, statusText(data), layout.Status.Color)
+		if img, ok := lookupIcon(layout.Status.Icon); ok {
+			// Right aligned, clear of the connection indicator
+			p := image.Pt(indicator.X-1-img.Bounds().Dx(), layout.Status.Y)
+			drawIcon(fb, p, img, layout.Status.Color)
+		}
+	} else {
+		textFace.Draw(fb, layout.IPAddress.X, layout.IPAddress.Y, data.IPAddress, layout.IPAddress.Color)
+	}
+
+	pos := data.MachineStatus.WorkCoordinates
+	coords := layout.Coordinates
+	textFace.Draw(fb, coords.X.X, coords.X.Y, fmt.Sprintf("X%8.2f", pos.X), coords.X.Color)
+	textFace.Draw(fb, coords.Y.X, coords.Y.Y, fmt.Sprintf("Y%8.2f", pos.Y), coords.Y.Color)
+	textFace.Draw(fb, coords.Z.X, coords.Z.Y, fmt.Sprintf("Z%8.2f", pos.Z), coords.Z.Color)
+}
+
+// drawSummaryColumn draws one machine of the summary view, framed when it
+// needs attention
+func drawSummaryColumn(fb *framebuffer.FrameBuffer, column SummaryColumnLayout) {
+	bounds := image.Rect(column.X, column.Y, column.X+column.Width, column.Y+column.Height)
+	clip := fb.SubImage(bounds)
+
+	textFace.Draw(clip, column.X+2, column.Y+2, column.Name, color.White)
+	textFace.Draw(clip, column.X+2, column.Y+2+lineHeight, string(column.State), column.Color)
+	if column.Highlight {
+		drawRect(clip, bounds, column.Color)
+	}
+}
+
+// statusText returns the machine state, with the alarm code if known
+func statusText(data types.DisplayData) string {
+	state := data.MachineStatus.State
+	if state == types.StateAlarm && data.AlarmCode > 0 {
+		return fmt.Sprintf("%s %d", state, data.AlarmCode)
+	}
+	return string(state)
+}
+
+// splashLines returns the lines of the startup splash page
+func splashLines(data types.DisplayData) []string {
+	lines := data.Controller.Summary()
+	if len(lines) == 0 {
+		lines = []string{"FluidNC", data.IPAddress}
+	}
+	return lines
+}
+
+// fillRect fills r with c
+func fillRect(fb *framebuffer.FrameBuffer, r image.Rectangle, c color.Color) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			fb.Set(x, y, c)
+		}
+	}
+}
+
+// drawRect draws the outline of r with c
+func drawRect(fb *framebuffer.FrameBuffer, r image.Rectangle, c color.Color) {
+	for x := r.Min.X; x < r.Max.X; x++ {
+		fb.Set(x, r.Min.Y, c)
+		fb.Set(x, r.Max.Y-1, c)
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		fb.Set(r.Min.X, y, c)
+		fb.Set(r.Max.X-1, y, c)
+	}
+}
+
+// GetDisplayLayout returns the layout for the display: the state, or the
+// address while disconnected, on the top line and one axis per line below
 func (r *Renderer) GetDisplayLayout(data types.DisplayData) DisplayLayout {
+	indicator := color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	if data.Connected {
+		indicator = color.RGBA{R: 0, G: 255, B: 0, A: 255}
+	}
+
 	return DisplayLayout{
 		IPAddress: IPAddressLayout{
-			X: r.cfg.Width - 10,
+			X: 0,
 			Y: 0,
 			Color: color.RGBA{
 				R: 255,
@@ -80,7 +329,7 @@ func (r *Renderer) GetDisplayLayout(data types.DisplayData) DisplayLayout {
 		Coordinates: CoordinatesLayout{
 			X: XCoordinateLayout{
 				X: 0,
-				Y: 5,
+				Y: lineHeight,
 				Color: color.RGBA{
 					R: 255,
 					G: 0,
@@ -90,7 +339,7 @@ func (r *Renderer) GetDisplayLayout(data types.DisplayData) DisplayLayout {
 			},
 			Y: YCoordinateLayout{
 				X: 0,
-				Y: 15,
+				Y: 2 * lineHeight,
 				Color: color.RGBA{
 					R: 0,
 					G: 255,
@@ -100,32 +349,37 @@ func (r *Renderer) GetDisplayLayout(data types.DisplayData) DisplayLayout {
 			},
 			Z: ZCoordinateLayout{
 				X: 0,
-				Y: 25,
+				Y: 3 * lineHeight,
 				Color: color.RGBA{
 					R: 0,
-					G: 0,
+					G: 128,
 					B: 255,
 					A: 255,
 				},
 			},
 		},
 		Status: StatusLayout{
-			X: 20,
-			Y: 25,
-			Color: color.RGBA{
-				R: 255,
-				G: 255,
-				B: 255,
-				A: 255,
-			},
+			X:     0,
+			Y:     0,
+			Color: stateColor(data.MachineStatus.State),
+			Icon:  stateIcon(data),
 		},
 		ConnectionIndicator: ConnectionIndicatorLayout{
-			X: r.cfg.Width - 2,
-			Y: 0,
+			X:         r.cfg.Width - 2,
+			Y:         0,
 			Connected: data.Connected,
+			Color:     indicator,
+		},
+		Splash: SplashLayout{
+			X: 0,
+			Y: 0,
+			// Shown at startup until the first status report arrives, and
+			// long enough to read the controller summary
+			Visible: data.MachineStatus.LastUpdated.IsZero() || r.now().Before(r.splashUntil[data.Name]),
+			Lines:   splashLines(data),
 			Color: color.RGBA{
-				R: 0,
-				G: 255,
+				R: 255,
+				G: 160,
 				B: 0,
 				A: 255,
 			},
@@ -139,6 +393,7 @@ type DisplayLayout struct {
 	Coordinates         CoordinatesLayout
 	Status              StatusLayout
 	ConnectionIndicator ConnectionIndicatorLayout
+	Splash              SplashLayout
 }
 
 // IPAddressLayout represents the layout for the IP address
@@ -181,6 +436,8 @@ type StatusLayout struct {
 	X     int
 	Y     int
 	Color color.Color
+	// Icon names the icon of the state drawn at the right, if any
+	Icon string
 }
 
 // ConnectionIndicatorLayout represents the layout for the connection indicator
@@ -189,4 +446,13 @@ type ConnectionIndicatorLayout struct {
 	Y         int
 	Connected bool
 	Color     color.Color
-} 
\ No newline at end of file
+}
+
+// SplashLayout represents the layout for the startup splash page
+type SplashLayout struct {
+	X       int
+	Y       int
+	Visible bool
+	Lines   []string
+	Color   color.Color
+}
@@ -0,0 +1,328 @@
+package display
+
+import (
+	"bufio"
+	"context"
+	"image"
+	"image/color"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/fluidnc"
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// newTestRenderer returns a 64x32 renderer drawing into an in-memory matrix
+// and a counter of the frames presented to it
+func newTestRenderer(view string) (*Renderer, *Mirror, *int) {
+	cfg := &types.DisplayConfig{Width: 64, Height: 32, MachineView: view}
+	mirror := NewMirror(nil, cfg.Width, cfg.Height)
+	presented := new(int)
+	mirror.Tap(func(*framebuffer.FrameBuffer) { *presented++ })
+
+	r := NewRenderer(cfg)
+	r.SetMatrix(mirror)
+	return r, mirror, presented
+}
+
+// countColor returns the number of pixels of c inside rect
+func countColor(fb *framebuffer.FrameBuffer, rect image.Rectangle, c color.RGBA) int {
+	n := 0
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if fb.RGBAt(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+var (
+	red    = color.RGBA{R: 255, A: 255}
+	green  = color.RGBA{G: 255, A: 255}
+	orange = color.RGBA{R: 255, G: 160, A: 255}
+)
+
+// TestRendererMachine tests drawing the splash page and then the machine
+func TestRendererMachine(t *testing.T) {
+	r, mirror, _ := newTestRenderer("")
+	data := types.DisplayData{Connected: true, IPAddress: "10.0.0.5"}
+
+	r.Update(data)
+	if err := r.render(); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if countColor(mirror.Last(), image.Rect(0, 0, 64, lineHeight), orange) == 0 {
+		t.Errorf("splash not drawn before the first status report")
+	}
+
+	data.MachineStatus = types.MachineStatus{
+		State:           types.StateIdle,
+		WorkCoordinates: types.Coordinates{X: 12.5},
+		LastUpdated:     time.Now(),
+	}
+	r.Update(data)
+	if err := r.render(); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+
+	fb := mirror.Last()
+	tests := []struct {
+		name  string
+		rect  image.Rectangle
+		color color.RGBA
+		want  bool
+	}{
+		{"status", image.Rect(0, 0, 60, lineHeight), color.RGBA{255, 255, 255, 255}, true},
+		{"indicator", image.Rect(62, 0, 64, 2), green, true},
+		{"x axis", image.Rect(0, lineHeight, 64, 2*lineHeight), red, true},
+		{"no splash", image.Rect(0, 0, 64, 32), orange, false},
+	}
+	for _, tt := range tests {
+		if got := countColor(fb, tt.rect, tt.color) > 0; got != tt.want {
+			t.Errorf("%s drawn = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestRendererSplashImage tests that the splash image is shown until the
+// first status report, and that the state icon follows the state
+func TestRendererSplashImage(t *testing.T) {
+	r, mirror, _ := newTestRenderer("")
+	logo := image.NewRGBA(image.Rect(0, 0, 64, 32))
+	for i := range logo.Pix {
+		logo.Pix[i] = 0xff
+	}
+	r.SetSplash(logo)
+
+	data := types.DisplayData{Connected: true}
+	steps := []struct {
+		name   string
+		update func()
+		rect   image.Rectangle
+		color  color.RGBA
+		want   bool
+	}{
+		{"logo", func() {}, image.Rect(0, 0, 64, 32), color.RGBA{255, 255, 255, 255}, true},
+		{"alarm icon", func() {
+			data.MachineStatus = types.MachineStatus{State: types.StateAlarm, LastUpdated: time.Now()}
+		}, image.Rect(54, 0, 61, 7), red, true},
+		{"no icon", func() { data.MachineStatus.State = types.StateIdle }, image.Rect(54, 0, 61, 7), color.RGBA{255, 255, 255, 255}, false},
+	}
+	for _, step := range steps {
+		step.update()
+		r.Update(data)
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+		if got := countColor(mirror.Last(), step.rect, step.color) > 0; got != step.want {
+			t.Errorf("%s drawn = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+// serveFakeController starts a telnet controller that answers the firmware
+// probe like FluidNC and reports Idle to every status poll
+func serveFakeController(t *testing.T) types.FluidNCConfig {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	replies := map[string]string{
+		"[ESP800]": "FW version: FluidNC v3.7.8 (wifi) # FW target:grbl-embedded # hostname:router # axis:3\r\nok",
+		"$I":       "[VER:3.7 FluidNC v3.7.8:]\r\n[OPT:PHS,35,254]\r\nok",
+		// Keep the client polling
+		"$Report/Interval=20": "error:3",
+	}
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		var line []byte
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			switch b {
+			case '?':
+				conn.Write([]byte("<Idle|MPos:0.000,0.000,0.000|FS:0,0>\r\n"))
+			case '\n':
+				reply, ok := replies[strings.TrimSpace(string(line))]
+				if !ok {
+					reply = "ok"
+				}
+				conn.Write([]byte(reply + "\r\n"))
+				line = line[:0]
+			default:
+				line = append(line, b)
+			}
+		}
+	}()
+
+	return types.FluidNCConfig{
+		Name:           "router",
+		Host:           "127.0.0.1",
+		Port:           l.Addr().(*net.TCPAddr).Port,
+		Transport:      fluidnc.TransportTelnet,
+		StatusInterval: 0.02,
+	}
+}
+
+// TestRendererControllerSplash tests that the controller summary probed by
+// a live client is shown on the splash page, and stays up for a while after
+// the first status report
+func TestRendererControllerSplash(t *testing.T) {
+	m, err := fluidnc.NewManager([]types.FluidNCConfig{serveFakeController(t)})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer m.Close()
+
+	r, mirror, _ := newTestRenderer("")
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	// Render every change, as cmd/display does, up to the first report
+	deadline := time.After(5 * time.Second)
+	for {
+		machines := m.Machines()
+		r.Update(machines...)
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+		if !machines[0].MachineStatus.LastUpdated.IsZero() {
+			break
+		}
+
+		select {
+		case <-m.Changed():
+		case <-deadline:
+			t.Fatalf("no status report, machine = %+v", machines[0])
+		}
+	}
+
+	if got := m.Machines()[0].Controller.Hostname; got != "router" {
+		t.Fatalf("Controller.Hostname = %q, want router", got)
+	}
+	// The summary has four lines; the fallback splash has two
+	if countColor(mirror.Last(), image.Rect(0, 2*lineHeight, 64, 4*lineHeight), orange) == 0 {
+		t.Error("controller summary not drawn")
+	}
+
+	now = now.Add(splashDuration)
+	if err := r.render(); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if countColor(mirror.Last(), image.Rect(0, 0, 64, 32), orange) > 0 {
+		t.Error("splash still drawn after splashDuration")
+	}
+	if countColor(mirror.Last(), image.Rect(0, 0, 60, lineHeight), color.RGBA{255, 255, 255, 255}) == 0 {
+		t.Error("status not drawn after the splash")
+	}
+}
+
+// TestRendererPresentsChanges tests that frames are only pushed when the
+// content changes
+func TestRendererPresentsChanges(t *testing.T) {
+	r, _, presented := newTestRenderer("")
+	data := machineData("router", types.StateIdle)
+	data.MachineStatus.LastUpdated = time.Now()
+
+	steps := []struct {
+		x    float64
+		want int
+	}{
+		{0, 1},
+		{0, 1},
+		{1, 2},
+	}
+	for _, step := range steps {
+		data.MachineStatus.WorkCoordinates.X = step.x
+		r.Update(data)
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+		if *presented != step.want {
+			t.Errorf("X=%v: presented %d frames, want %d", step.x, *presented, step.want)
+		}
+	}
+}
+
+// dirtyMatrix records the dirty region of every frame presented to it
+type dirtyMatrix struct {
+	dirty []image.Rectangle
+}
+
+func (m *dirtyMatrix) Clear() error                           { return nil }
+func (m *dirtyMatrix) SetPixel(x, y int, c color.Color) error { return nil }
+func (m *dirtyMatrix) Show() error                            { return nil }
+func (m *dirtyMatrix) Close() error                           { return nil }
+
+func (m *dirtyMatrix) Present(fb *framebuffer.FrameBuffer) error {
+	m.dirty = append(m.dirty, fb.Dirty())
+	return nil
+}
+
+// TestRendererDirty tests that only the changed part of a frame is dirty
+func TestRendererDirty(t *testing.T) {
+	cfg := &types.DisplayConfig{Width: 64, Height: 32}
+	matrix := &dirtyMatrix{}
+	r := NewRenderer(cfg)
+	r.SetMatrix(matrix)
+
+	data := machineData("router", types.StateIdle)
+	data.MachineStatus.LastUpdated = time.Now()
+	for _, x := range []float64{0, 1} {
+		data.MachineStatus.WorkCoordinates.X = x
+		r.Update(data)
+		if err := r.render(); err != nil {
+			t.Fatalf("render() error = %v", err)
+		}
+	}
+
+	if len(matrix.dirty) != 2 {
+		t.Fatalf("presented %d frames, want 2", len(matrix.dirty))
+	}
+	full := image.Rect(0, 0, cfg.Width, cfg.Height)
+	if matrix.dirty[0] != full {
+		t.Errorf("first frame dirty = %v, want %v", matrix.dirty[0], full)
+	}
+	if d := matrix.dirty[1]; d.Empty() || d == full || !d.In(full) {
+		t.Errorf("second frame dirty = %v, want part of %v", d, full)
+	}
+}
+
+// TestRendererSummary tests that a machine in alarm is framed in the
+// summary view
+func TestRendererSummary(t *testing.T) {
+	r, mirror, _ := newTestRenderer(ViewSummary)
+	r.Update(machineData("router", types.StateIdle), machineData("laser", types.StateAlarm))
+	if err := r.render(); err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+
+	fb := mirror.Last()
+	if got := countColor(fb, image.Rect(32, 16, 33, 17), red); got != 1 {
+		t.Errorf("alarm column not framed")
+	}
+	if got := countColor(fb, image.Rect(0, 0, 32, 32), red); got != 0 {
+		t.Errorf("idle column has %d red pixels, want 0", got)
+	}
+}
@@ -0,0 +1,41 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/fonts"
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	_ "github.com/fkcurrie/fluidnc-led-golang/pkg/asset/svg"
+)
+
+// Setup creates a renderer for cfg and loads the fonts, icons, splash
+// image and layout it names. A splash image that fails to load is logged
+// and left out; anything else is an error.
+func Setup(cfg *types.DisplayConfig) (*Renderer, error) {
+	if err := fonts.Register(cfg.Fonts); err != nil {
+		return nil, fmt.Errorf("failed to load fonts: %w", err)
+	}
+	if err := RegisterIcons(cfg.Icons); err != nil {
+		return nil, fmt.Errorf("failed to load icons: %w", err)
+	}
+
+	renderer := NewRenderer(cfg)
+	if cfg.Splash.Path != "" {
+		splash, err := LoadImage(cfg.Splash, image.Pt(cfg.Width, cfg.Height))
+		if err != nil {
+			log.Printf("Not showing a splash image: %v", err)
+		} else {
+			renderer.SetSplash(splash)
+		}
+	}
+	if cfg.Layout != "" {
+		screens, err := LoadScreens(cfg.Layout, cfg.Width, cfg.Height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load layout: %w", err)
+		}
+		renderer.SetScreens(screens)
+	}
+	return renderer, nil
+}
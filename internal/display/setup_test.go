@@ -0,0 +1,37 @@
+package display
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestSetup tests loading the assets named in the display configuration
+func TestSetup(t *testing.T) {
+	examples := filepath.Join("..", "..", "examples")
+	cfg := &types.DisplayConfig{
+		Width:  64,
+		Height: 32,
+		Layout: filepath.Join(examples, "layout.json"),
+		Splash: types.ImageConfig{Path: filepath.Join(examples, "fluidnc-logo.svg")},
+	}
+
+	r, err := Setup(cfg)
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if len(r.screens) == 0 || r.splash == nil {
+		t.Errorf("Setup() screens = %d, splash = %v, want both loaded", len(r.screens), r.splash != nil)
+	}
+
+	// A missing splash is not fatal, a missing layout is
+	cfg.Splash.Path = filepath.Join(examples, "missing.svg")
+	if r, err := Setup(cfg); err != nil || r.splash != nil {
+		t.Errorf("Setup() with a missing splash = %v, %v", r, err)
+	}
+	cfg.Layout = filepath.Join(examples, "missing.json")
+	if _, err := Setup(cfg); err == nil {
+		t.Error("Setup() with a missing layout did not return error")
+	}
+}
@@ -0,0 +1,166 @@
+package display
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// PNGMatrix is a simulated matrix that writes each shown frame to a PNG
+// file, so layouts can be developed without a panel attached
+type PNGMatrix struct {
+	cfg   types.SimulatorConfig
+	fb    *framebuffer.FrameBuffer
+	frame int
+	mu    sync.Mutex
+}
+
+// NewPNGMatrix creates a simulated matrix writing frames to cfg.Dir
+func NewPNGMatrix(width, height int, cfg types.SimulatorConfig) (*PNGMatrix, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid dimensions: %dx%d", width, height)
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "."
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+
+	return &PNGMatrix{
+		cfg: cfg,
+		fb:  framebuffer.New(width, height),
+	}, nil
+}
+
+// Clear clears the matrix
+func (m *PNGMatrix) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Clear()
+	return nil
+}
+
+// SetPixel sets a pixel at the given coordinates to the given color
+func (m *PNGMatrix) SetPixel(x, y int, c color.Color) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !(image.Point{x, y}.In(m.fb.Bounds())) {
+		return fmt.Errorf("coordinates out of bounds: (%d, %d)", x, y)
+	}
+	m.fb.Set(x, y, c)
+	return nil
+}
+
+// Show writes the current buffer to the next frame file
+func (m *PNGMatrix) Show() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.write()
+}
+
+// Present copies a whole frame and writes it
+func (m *PNGMatrix) Present(fb *framebuffer.FrameBuffer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Blit(image.Point{}, fb, fb.Bounds())
+	return m.write()
+}
+
+// Close closes the matrix
+func (m *PNGMatrix) Close() error {
+	return nil
+}
+
+// LastFrame returns the path of the most recently written frame
+func (m *PNGMatrix) LastFrame() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.framePath(m.frame)
+}
+
+// framePath returns the path of frame n
+func (m *PNGMatrix) framePath(n int) string {
+	if !m.cfg.Sequence {
+		return filepath.Join(m.cfg.Dir, "frame.png")
+	}
+	return filepath.Join(m.cfg.Dir, fmt.Sprintf("frame-%06d.png", n))
+}
+
+// write encodes the buffer to the next frame, replacing it atomically so a
+// viewer never sees a partial file
+func (m *PNGMatrix) write() error {
+	m.frame++
+	path := m.framePath(m.frame)
+
+	tmp, err := os.CreateTemp(m.cfg.Dir, ".frame-*")
+	if err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := png.Encode(tmp, RenderLEDs(m.fb, m.cfg.Pitch, m.cfg.Dots)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+	return nil
+}
+
+// RenderLEDs upscales a frame so each LED is pitch pixels wide. With dots
+// set each LED is drawn as a round dot on black, like a real panel.
+func RenderLEDs(fb *framebuffer.FrameBuffer, pitch int, dots bool) *image.RGBA {
+	if pitch < 1 {
+		pitch = 1
+	}
+	bounds := fb.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*pitch, bounds.Dy()*pitch))
+
+	// Offsets inside one LED cell that are lit
+	var lit []image.Point
+	radius := float64(pitch)/2 - 0.25
+	center := float64(pitch-1) / 2
+	for dy := 0; dy < pitch; dy++ {
+		for dx := 0; dx < pitch; dx++ {
+			x, y := float64(dx)-center, float64(dy)-center
+			if !dots || pitch < 3 || x*x+y*y <= radius*radius {
+				lit = append(lit, image.Pt(dx, dy))
+			}
+		}
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := fb.RGBAt(x, y)
+			ox, oy := (x-bounds.Min.X)*pitch, (y-bounds.Min.Y)*pitch
+			for _, p := range lit {
+				img.SetRGBA(ox+p.X, oy+p.Y, c)
+			}
+		}
+	}
+
+	// Unlit space between dots is black
+	if dots && pitch >= 3 {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img
+}
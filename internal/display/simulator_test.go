@@ -0,0 +1,89 @@
+package display
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// readPNG decodes a frame written by the simulator
+func readPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+// TestPNGMatrix tests that shown frames are written upscaled
+func TestPNGMatrix(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewPNGMatrix(4, 2, types.SimulatorConfig{Dir: dir, Pitch: 4, Sequence: true})
+	if err != nil {
+		t.Fatalf("NewPNGMatrix() error = %v", err)
+	}
+
+	red := color.RGBA{R: 255, A: 255}
+	if err := m.SetPixel(1, 0, red); err != nil {
+		t.Fatalf("SetPixel() error = %v", err)
+	}
+	if err := m.SetPixel(4, 0, red); err == nil {
+		t.Error("SetPixel() out of bounds did not return error")
+	}
+	if err := m.Show(); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+
+	if want := filepath.Join(dir, "frame-000001.png"); m.LastFrame() != want {
+		t.Errorf("LastFrame() = %q, want %q", m.LastFrame(), want)
+	}
+	img := readPNG(t, m.LastFrame())
+	if img.Bounds() != image.Rect(0, 0, 16, 8) {
+		t.Fatalf("frame bounds = %v, want 16x8", img.Bounds())
+	}
+	if got := color.RGBAModel.Convert(img.At(7, 3)); got != red {
+		t.Errorf("frame At(7, 3) = %v, want %v", got, red)
+	}
+	if got := color.RGBAModel.Convert(img.At(8, 3)); got != (color.RGBA{A: 255}) {
+		t.Errorf("frame At(8, 3) = %v, want black", got)
+	}
+
+	// Present replaces the whole frame
+	fb := framebuffer.New(4, 2)
+	fb.Fill(color.RGBA{B: 255, A: 255})
+	if err := m.Present(fb); err != nil {
+		t.Fatalf("Present() error = %v", err)
+	}
+	img = readPNG(t, filepath.Join(dir, "frame-000002.png"))
+	if got := color.RGBAModel.Convert(img.At(7, 3)); got != (color.RGBA{B: 255, A: 255}) {
+		t.Errorf("presented At(7, 3) = %v, want blue", got)
+	}
+}
+
+// TestRenderLEDsDots tests that dots leave dark corners between LEDs
+func TestRenderLEDsDots(t *testing.T) {
+	fb := framebuffer.New(1, 1)
+	fb.Fill(color.White)
+
+	img := RenderLEDs(fb, 8, true)
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("corner = %v, want black", got)
+	}
+	if got := img.RGBAAt(4, 4); got != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("center = %v, want white", got)
+	}
+}
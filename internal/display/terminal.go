@@ -0,0 +1,139 @@
+package display
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"sync"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// ANSI sequences used by the terminal matrix
+const (
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiReset      = "\x1b[0m"
+	// The alternate screen keeps the frame apart from log output and the
+	// shell, which are restored when it is left
+	ansiEnterAltScreen = "\x1b[?1049h"
+	ansiLeaveAltScreen = "\x1b[?1049l"
+	ansiHome           = "\x1b[H"
+	// halfBlock draws the top half of a cell in the foreground color and
+	// the bottom half in the background color
+	halfBlock = "▀"
+)
+
+// TerminalMatrix is a simulated matrix drawn in a terminal with 24-bit ANSI
+// colors, two pixels per character cell, redrawn in place on every Show in
+// the terminal's alternate screen
+type TerminalMatrix struct {
+	out   io.Writer
+	fb    *framebuffer.FrameBuffer
+	drawn bool
+	mu    sync.Mutex
+}
+
+// NewTerminalMatrix creates a terminal matrix writing to out
+func NewTerminalMatrix(width, height int, out io.Writer) (*TerminalMatrix, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid dimensions: %dx%d", width, height)
+	}
+	return &TerminalMatrix{
+		out: out,
+		fb:  framebuffer.New(width, height),
+	}, nil
+}
+
+// Clear clears the matrix
+func (m *TerminalMatrix) Clear() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Clear()
+	return nil
+}
+
+// SetPixel sets a pixel at the given coordinates to the given color
+func (m *TerminalMatrix) SetPixel(x, y int, c color.Color) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !(image.Point{x, y}.In(m.fb.Bounds())) {
+		return fmt.Errorf("coordinates out of bounds: (%d, %d)", x, y)
+	}
+	m.fb.Set(x, y, c)
+	return nil
+}
+
+// Show draws the current buffer over the previous frame
+func (m *TerminalMatrix) Show() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.draw()
+}
+
+// Present copies a whole frame and draws it
+func (m *TerminalMatrix) Present(fb *framebuffer.FrameBuffer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.fb.Blit(image.Point{}, fb, fb.Bounds())
+	return m.draw()
+}
+
+// Close restores the cursor and the normal screen
+func (m *TerminalMatrix) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.drawn {
+		return nil
+	}
+	_, err := io.WriteString(m.out, ansiReset+ansiShowCursor+ansiLeaveAltScreen)
+	return err
+}
+
+// draw writes the frame as one block so the terminal updates at once
+func (m *TerminalMatrix) draw() error {
+	var buf bytes.Buffer
+	rows := (m.fb.Height() + 1) / 2
+
+	if !m.drawn {
+		buf.WriteString(ansiEnterAltScreen + ansiHideCursor)
+	}
+	buf.WriteString(ansiHome)
+
+	black := color.RGBA{A: 0xff}
+	for row := 0; row < rows; row++ {
+		var fg, bg color.RGBA
+		started := false
+		for x := 0; x < m.fb.Width(); x++ {
+			top := m.fb.RGBAt(x, 2*row)
+			bottom := black
+			if 2*row+1 < m.fb.Height() {
+				bottom = m.fb.RGBAt(x, 2*row+1)
+			}
+
+			// Only emit colors that changed since the previous cell
+			if !started || top != fg {
+				fmt.Fprintf(&buf, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+				fg = top
+			}
+			if !started || bottom != bg {
+				fmt.Fprintf(&buf, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+				bg = bottom
+			}
+			started = true
+			buf.WriteString(halfBlock)
+		}
+		buf.WriteString(ansiReset + "\n")
+	}
+
+	m.drawn = true
+	_, err := m.out.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,49 @@
+package display
+
+import (
+	"bytes"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
+
+// TestTerminalMatrix tests half-block drawing and in-place redraws
+func TestTerminalMatrix(t *testing.T) {
+	var out bytes.Buffer
+	m, err := NewTerminalMatrix(2, 3, &out)
+	if err != nil {
+		t.Fatalf("NewTerminalMatrix() error = %v", err)
+	}
+
+	fb := framebuffer.New(2, 3)
+	fb.Set(0, 0, color.RGBA{R: 255, A: 255})
+	fb.Set(0, 1, color.RGBA{B: 255, A: 255})
+	if err := m.Present(fb); err != nil {
+		t.Fatalf("Present() error = %v", err)
+	}
+
+	want := ansiEnterAltScreen + ansiHideCursor + ansiHome +
+		"\x1b[38;2;255;0;0m\x1b[48;2;0;0;255m" + halfBlock +
+		"\x1b[38;2;0;0;0m\x1b[48;2;0;0;0m" + halfBlock + ansiReset + "\n" +
+		"\x1b[38;2;0;0;0m\x1b[48;2;0;0;0m" + halfBlock + halfBlock + ansiReset + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("first frame = %q, want %q", got, want)
+	}
+
+	// The next frame is drawn over the first
+	out.Reset()
+	if err := m.Show(); err != nil {
+		t.Fatalf("Show() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), ansiHome+"\x1b[38;2;255;0;0m") {
+		t.Errorf("second frame = %q, want it to start at the top left", out.String())
+	}
+
+	out.Reset()
+	m.Close()
+	if out.String() != ansiReset+ansiShowCursor+ansiLeaveAltScreen {
+		t.Errorf("Close() wrote %q", out.String())
+	}
+}
@@ -0,0 +1,36 @@
+package fluidnc
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	// minBackoff is the delay before the first reconnect attempt
+	minBackoff = 500 * time.Millisecond
+	// defaultMaxBackoff is used when ReconnectInterval is not configured
+	defaultMaxBackoff = 5 * time.Second
+	// stableConnection is how long a connection that has not delivered a
+	// status report must stay up before the backoff starts over
+	stableConnection = 10 * time.Second
+)
+
+// backoffDelay returns the delay before reconnect attempt n (starting at 0).
+// The delay doubles with each attempt up to max, and half of it is
+// randomised so that several monitors do not hammer a controller in step.
+func backoffDelay(attempt int, max time.Duration) time.Duration {
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	d := minBackoff
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,448 @@
+package fluidnc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// Client represents a FluidNC client
+type Client struct {
+	config     types.FluidNCConfig
+	conn       Transport
+	statusChan chan types.MachineStatus
+	stateChan  chan types.ConnectionState
+	eventChan  chan Event
+	done       chan struct{}
+	closeOnce  sync.Once
+	parser     statusParser
+	job        JobTracker
+	info       types.ControllerInfo
+	mu         sync.Mutex
+	state      types.ConnectionState
+	startup    []string
+	writeChan  chan []byte
+	// realtimeChan bypasses writeChan so real-time bytes are never stuck
+	// behind queued line commands
+	realtimeChan chan byte
+	cmdMu        sync.Mutex
+	pending      []*pendingCommand
+}
+
+// NewClient creates a new FluidNC client
+func NewClient(config types.FluidNCConfig) *Client {
+	return &Client{
+		config:       config,
+		statusChan:   make(chan types.MachineStatus, 1),
+		stateChan:    make(chan types.ConnectionState, 10),
+		eventChan:    make(chan Event, 32),
+		writeChan:    make(chan []byte, 64),
+		realtimeChan: make(chan byte, 64),
+		done:         make(chan struct{}),
+		state:        types.ConnectionDisconnected,
+	}
+}
+
+// AddStartupQuery adds a command that is sent every time a connection is
+// established, including after a reconnect
+func (c *Client) AddStartupQuery(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startup = append(c.startup, query)
+}
+
+// Connect starts the connection manager, which connects to the FluidNC
+// controller over the configured transport and keeps reconnecting until the
+// context is cancelled, the client is closed or MaxReconnectAttempts is
+// exceeded
+func (c *Client) Connect(ctx context.Context) error {
+	if err := validateConfig(c.config); err != nil {
+		return err
+	}
+
+	go c.run(ctx)
+
+	return nil
+}
+
+// Disconnect stops the connection manager and closes the connection
+func (c *Client) Disconnect() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// Status returns a channel that receives machine status updates. Updates
+// are coalesced: a slow reader only sees the latest status.
+func (c *Client) Status() <-chan types.MachineStatus {
+	return c.statusChan
+}
+
+// Job returns the progress of the current or last job
+func (c *Client) Job() types.JobStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.job.Job()
+}
+
+// Info returns what is known about the controller firmware on the current
+// connection
+func (c *Client) Info() types.ControllerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info
+}
+
+// Events returns a channel that receives every line from the controller
+// other than status reports, classified by kind
+func (c *Client) Events() <-chan Event {
+	return c.eventChan
+}
+
+// State returns a channel that receives connection state transitions
+func (c *Client) State() <-chan types.ConnectionState {
+	return c.stateChan
+}
+
+// Connected reports whether the client currently has a live connection
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state == types.ConnectionConnected
+}
+
+// Close closes the client
+func (c *Client) Close() {
+	c.Disconnect()
+}
+
+// setState records a connection state transition and publishes it
+func (c *Client) setState(state types.ConnectionState) {
+	c.mu.Lock()
+	changed := c.state != state
+	c.state = state
+	c.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	select {
+	case c.stateChan <- state:
+	default:
+		// Channel is full, skip this update
+	}
+}
+
+// SetHost points the client at a controller that moved, e.g. after a DHCP
+// lease change, and reconnects to it
+func (c *Client) SetHost(host string) {
+	c.mu.Lock()
+	c.config.Host = host
+	conn := c.conn
+	c.mu.Unlock()
+
+	// Dropping the connection makes run dial the new address
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// Host returns the address the client connects to
+func (c *Client) Host() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.Host
+}
+
+// currentConfig returns a copy of the configuration safe to use while
+// SetHost may change it
+func (c *Client) currentConfig() types.FluidNCConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config
+}
+
+// run connects and reconnects to the server with exponential backoff
+func (c *Client) run(ctx context.Context) {
+	maxDelay := time.Duration(c.config.ReconnectInterval) * time.Second
+	failures := 0
+
+	for {
+		c.setState(types.ConnectionConnecting)
+
+		// A connection that is dropped before it delivers a status report
+		// or stays up for stableConnection counts as a failed attempt, so a
+		// controller that accepts and hangs up keeps backing off
+		conn, err := dialTransport(ctx, c.currentConfig())
+		if err == nil {
+			start := time.Now()
+			if c.serve(ctx, conn) || time.Since(start) >= stableConnection {
+				failures = 0
+			} else {
+				err = fmt.Errorf("connection closed after %v", time.Since(start).Round(time.Millisecond))
+			}
+		}
+		if err != nil && !c.stopping(ctx) {
+			failures++
+			log.Printf("failed to connect to FluidNC (attempt %d): %v", failures, err)
+
+			if c.config.MaxReconnectAttempts > 0 && failures >= c.config.MaxReconnectAttempts {
+				c.setState(types.ConnectionGivingUp)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			c.setState(types.ConnectionDisconnected)
+			return
+		case <-c.done:
+			c.setState(types.ConnectionDisconnected)
+			return
+		case <-time.After(backoffDelay(failures, maxDelay)):
+		}
+	}
+}
+
+// stopping reports whether the client is shutting down
+func (c *Client) stopping(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// serve runs the read and write pumps until the connection fails and
+// reports whether a status report was received
+func (c *Client) serve(ctx context.Context, conn Transport) bool {
+	c.mu.Lock()
+	c.conn = conn
+	startup := append([]string(nil), c.startup...)
+	c.mu.Unlock()
+
+	// Cached offsets and firmware details may be stale after a reconnect
+	c.parser = statusParser{}
+	c.mu.Lock()
+	c.info = types.ControllerInfo{}
+	c.mu.Unlock()
+
+	// Probe the firmware first; its answer decides whether to ask for
+	// automatic status reports. That is the only behaviour it changes: the
+	// status parser takes whichever fields and axes a report carries, and no
+	// telnet or WebSocket handling depends on the firmware yet.
+	probed := make(chan error, 1)
+	cmds := []*pendingCommand{
+		{line: probeESP800},
+		{line: probeBuild, reply: probed},
+	}
+	for _, query := range startup {
+		cmds = append(cmds, &pendingCommand{line: query})
+	}
+
+	// Commands are accepted once the startup queries are ahead of them
+	c.queueStartup(cmds)
+	c.setState(types.ConnectionConnected)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.writePump(ctx, conn, stop, cmds, probed)
+	}()
+
+	reported := c.readPump(conn)
+	close(stop)
+	wg.Wait()
+
+	c.mu.Lock()
+	c.conn = nil
+	c.mu.Unlock()
+
+	c.setState(types.ConnectionLost)
+	c.failPending(ErrConnectionLost)
+	c.dropWrites()
+	c.dropRealtime()
+	return reported
+}
+
+// statusInterval returns the configured status interval
+func (c *Client) statusInterval() time.Duration {
+	if c.config.StatusInterval <= 0 {
+		return defaultStatusInterval
+	}
+	return time.Duration(c.config.StatusInterval * float64(time.Second))
+}
+
+// publishStatus sends a status to consumers, replacing any update they
+// have not read yet so that only the latest status is delivered
+func (c *Client) publishStatus(status types.MachineStatus) {
+	for {
+		select {
+		case c.statusChan <- status:
+			return
+		default:
+		}
+
+		select {
+		case <-c.statusChan:
+		default:
+		}
+	}
+}
+
+// readPump pumps lines from the connection to the status and event
+// channels and reports whether a status report was received
+func (c *Client) readPump(conn Transport) (reported bool) {
+	defer conn.Close()
+
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) && !errors.Is(err, os.ErrClosed) {
+				log.Printf("error reading from FluidNC: %v", err)
+			}
+			return reported
+		}
+
+		// Parse the message
+		event, err := c.parser.parseLine(line)
+		if err != nil {
+			log.Printf("error parsing %q: %v", line, err)
+			// Every error: line answers a command, even one whose code is
+			// garbled, or later replies would go to the wrong commands
+			if event.Kind == EventError {
+				c.resolvePending(event)
+			}
+			continue
+		}
+
+		if event.Kind == EventStatus {
+			c.mu.Lock()
+			c.job.Update(event.Status)
+			c.mu.Unlock()
+
+			c.publishStatus(event.Status)
+			reported = true
+			continue
+		}
+
+		c.mu.Lock()
+		updateInfo(&c.info, event)
+		c.mu.Unlock()
+
+		switch event.Kind {
+		case EventOK, EventError:
+			c.resolvePending(event)
+		case EventAlarm:
+			log.Printf("FluidNC %s", event)
+		}
+
+		select {
+		case c.eventChan <- event:
+		default:
+			// Channel is full, skip this event
+		}
+	}
+}
+
+// writePump sends startup queries, commands, status requests and pings to
+// the connection
+func (c *Client) writePump(ctx context.Context, conn Transport, stop <-chan struct{}, startup []*pendingCommand, probed <-chan error) {
+	ping := time.NewTicker(pingPeriod)
+	poll := time.NewTicker(c.statusInterval())
+	defer func() {
+		ping.Stop()
+		poll.Stop()
+		conn.Close()
+	}()
+
+	for _, cmd := range startup {
+		if err := conn.Write([]byte(cmd.line + "\n")); err != nil {
+			return
+		}
+	}
+
+	var autoReport chan error
+	for {
+		// Real-time bytes go ahead of anything else that is ready
+		select {
+		case b := <-c.realtimeChan:
+			if err := conn.Write([]byte{b}); err != nil {
+				return
+			}
+			continue
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		case <-stop:
+			return
+		case <-probed:
+			probed = nil
+			info := c.Info()
+			log.Printf("FluidNC controller: %s %s", info.Firmware, info.Version)
+			if !info.IsFluidNC() {
+				// Classic Grbl has no $Report/Interval
+				continue
+			}
+
+			// Negotiate through the command queue so the reply is
+			// correlated; the result arrives on autoReport
+			autoReport = make(chan error, 1)
+			go func(result chan<- error) {
+				result <- c.SendCommand(ctx, fmt.Sprintf("$Report/Interval=%d", c.statusInterval().Milliseconds()))
+			}(autoReport)
+		case err := <-autoReport:
+			autoReport = nil
+			if err != nil {
+				log.Printf("FluidNC automatic reporting unavailable, polling: %v", err)
+				continue
+			}
+			// Reports are pushed now; keep polling slowly so an idle
+			// machine still proves the connection is alive
+			poll.Reset(heartbeatInterval)
+		case b := <-c.realtimeChan:
+			if err := conn.Write([]byte{b}); err != nil {
+				return
+			}
+		case data := <-c.writeChan:
+			if err := conn.Write(data); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.Ping(); err != nil {
+				return
+			}
+		case <-poll.C:
+			if err := conn.Write([]byte{RealtimeStatus}); err != nil {
+				return
+			}
+		}
+	}
+}
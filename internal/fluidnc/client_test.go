@@ -0,0 +1,251 @@
+package fluidnc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/gorilla/websocket"
+)
+
+// TestBackoffDelay tests that the reconnect delay grows and is bounded
+func TestBackoffDelay(t *testing.T) {
+	max := 4 * time.Second
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDelay(attempt, max)
+		if d <= 0 || d > max {
+			t.Errorf("backoffDelay(%d) = %v, want in (0, %v]", attempt, d, max)
+		}
+	}
+
+	if d := backoffDelay(10, max); d < max/2 {
+		t.Errorf("backoffDelay(10) = %v, want at least %v", d, max/2)
+	}
+	if d := backoffDelay(0, max); d > minBackoff {
+		t.Errorf("backoffDelay(0) = %v, want at most %v", d, minBackoff)
+	}
+}
+
+// newTestServer starts a WebSocket server that sends a status report and
+// drops the first n connections after it
+func newTestServer(t *testing.T, drop int32, queries chan<- string) (*httptest.Server, types.FluidNCConfig) {
+	t.Helper()
+
+	var upgrader websocket.Upgrader
+	var conns int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		n := atomic.AddInt32(&conns, 1)
+		conn.WriteMessage(websocket.TextMessage, []byte("<Idle|MPos:0.000,0.000,0.000|FS:0,0>\n"))
+		if n <= drop {
+			return
+		}
+
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if strings.HasPrefix(string(msg), "$G") {
+				queries <- string(msg)
+			}
+		}
+	}))
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return srv, types.FluidNCConfig{
+		Host:              host,
+		Port:              p,
+		Transport:         TransportWebSocket,
+		ReconnectInterval: 1,
+		StatusInterval:    0.05,
+	}
+}
+
+// waitState waits for the client to publish the given connection state
+func waitState(t *testing.T, c *Client, want types.ConnectionState) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case s := <-c.State():
+			if s == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for state %s", want)
+		}
+	}
+}
+
+// TestClientReconnect tests that the client reconnects and replays startup queries
+func TestClientReconnect(t *testing.T) {
+	queries := make(chan string, 10)
+	srv, cfg := newTestServer(t, 1, queries)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := NewClient(cfg)
+	client.AddStartupQuery("$G")
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	waitState(t, client, types.ConnectionConnected)
+	waitState(t, client, types.ConnectionLost)
+	waitState(t, client, types.ConnectionConnected)
+
+	select {
+	case q := <-queries:
+		if q != "$G\n" {
+			t.Errorf("startup query = %q, want %q", q, "$G\n")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("startup query was not re-sent after reconnect")
+	}
+
+	if !client.Connected() {
+		t.Error("Connected() = false after reconnect")
+	}
+
+	select {
+	case status := <-client.Status():
+		if status.State != types.StateIdle {
+			t.Errorf("State = %s, want Idle", status.State)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status received")
+	}
+
+	cancel()
+	waitState(t, client, types.ConnectionDisconnected)
+}
+
+// TestClientGivesUp tests that the client stops after MaxReconnectAttempts
+func TestClientGivesUp(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	l.Close()
+
+	client := NewClient(types.FluidNCConfig{
+		Host:                 "127.0.0.1",
+		Port:                 addr.Port,
+		ReconnectInterval:    1,
+		MaxReconnectAttempts: 2,
+	})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	waitState(t, client, types.ConnectionGivingUp)
+}
+
+// TestClientBacksOffDroppedConnections tests that connections closed
+// before the first status report count towards MaxReconnectAttempts
+func TestClientBacksOffDroppedConnections(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	client := NewClient(types.FluidNCConfig{
+		Host:                 "127.0.0.1",
+		Port:                 l.Addr().(*net.TCPAddr).Port,
+		Transport:            TransportTelnet,
+		ReconnectInterval:    1,
+		MaxReconnectAttempts: 2,
+	})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	waitState(t, client, types.ConnectionGivingUp)
+}
+
+// TestPublishStatusCoalesces tests that a slow reader only sees the latest status
+func TestPublishStatusCoalesces(t *testing.T) {
+	client := NewClient(types.FluidNCConfig{})
+	for i := 1; i <= 5; i++ {
+		client.publishStatus(types.MachineStatus{LineNumber: i})
+	}
+
+	status := <-client.Status()
+	if status.LineNumber != 5 {
+		t.Errorf("LineNumber = %d, want 5", status.LineNumber)
+	}
+	select {
+	case status := <-client.Status():
+		t.Errorf("unexpected stale status %d", status.LineNumber)
+	default:
+	}
+}
+
+// TestAutoReportNegotiation tests that polling stops when the controller
+// accepts $Report/Interval and continues when it is rejected
+func TestAutoReportNegotiation(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		build   string
+		polling bool
+	}{
+		{name: "accepted", reply: "ok", polling: false},
+		{name: "rejected", reply: "error:3", polling: true},
+		{name: "classic grbl", reply: "ok", build: "[VER:1.1h.20190825:]\r\n[OPT:V,15,128]\r\nok", polling: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replies := map[string]string{"$Report/Interval=20": tt.reply}
+			if tt.build != "" {
+				replies[probeBuild] = tt.build
+			}
+			f := newFakeController(t, replies)
+			defer f.listener.Close()
+
+			client := connectClient(t, f.config())
+			defer client.Close()
+
+			time.Sleep(300 * time.Millisecond)
+			polls := atomic.LoadInt32(&f.polls)
+			if tt.polling && polls < 5 {
+				t.Errorf("polls = %d, want polling to continue", polls)
+			}
+			if !tt.polling && polls > 2 {
+				t.Errorf("polls = %d, want polling to stop", polls)
+			}
+		})
+	}
+}
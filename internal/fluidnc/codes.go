@@ -0,0 +1,113 @@
+package fluidnc
+
+import "fmt"
+
+// alarmCodes describes the ALARM:n codes sent by Grbl and FluidNC
+var alarmCodes = map[int]string{
+	1:  "Hard limit",
+	2:  "Soft limit",
+	3:  "Abort during cycle",
+	4:  "Probe fail",
+	5:  "Probe fail",
+	6:  "Homing fail: reset",
+	7:  "Homing fail: door",
+	8:  "Homing fail: pull off",
+	9:  "Homing fail: approach",
+	10: "Spindle control",
+	11: "Control pin on",
+	12: "Ambiguous switch",
+	13: "Hard stop",
+	14: "Unhomed",
+	15: "Init",
+}
+
+// errorCodes describes the error:n codes sent by Grbl and FluidNC
+var errorCodes = map[int]string{
+	1:   "Expected command letter",
+	2:   "Bad number format",
+	3:   "Invalid statement",
+	4:   "Negative value",
+	5:   "Homing not enabled",
+	6:   "Step pulse too short",
+	7:   "Settings read fail",
+	8:   "Not idle",
+	9:   "G-code lock",
+	10:  "Soft limits need homing",
+	11:  "Line overflow",
+	12:  "Step rate too high",
+	13:  "Check door",
+	14:  "Line length exceeded",
+	15:  "Travel exceeded",
+	16:  "Invalid jog command",
+	17:  "Laser mode needs PWM",
+	18:  "No homing cycle",
+	19:  "Single axis homing",
+	20:  "Unsupported command",
+	21:  "Modal group violation",
+	22:  "Undefined feed rate",
+	23:  "Value not integer",
+	24:  "Axis command conflict",
+	25:  "Word repeated",
+	26:  "No axis words",
+	27:  "Invalid line number",
+	28:  "Value word missing",
+	29:  "Unsupported coordinate system",
+	30:  "G53 invalid motion mode",
+	31:  "Axis words exist",
+	32:  "No axis words in plane",
+	33:  "Invalid target",
+	34:  "Arc radius error",
+	35:  "No offsets in plane",
+	36:  "Unused words",
+	37:  "G43 dynamic axis error",
+	38:  "Invalid tool number",
+	60:  "SD failed to mount",
+	61:  "SD failed to read",
+	62:  "SD failed to open directory",
+	63:  "SD directory not found",
+	64:  "SD file empty",
+	65:  "SD file not found",
+	66:  "SD failed to open file",
+	67:  "SD card busy",
+	68:  "SD failed to delete directory",
+	69:  "SD failed to delete file",
+	70:  "Bluetooth failed to start",
+	71:  "WiFi failed to start",
+	80:  "Number out of range",
+	81:  "Invalid value",
+	90:  "Message failed",
+	100: "Failed to store setting",
+	101: "Failed to get setting status",
+	110: "Authentication failed",
+	120: "Another interface is busy",
+	130: "Jog cancelled",
+	150: "Bad pin specification",
+	151: "Bad runtime config setting",
+	152: "Configuration is invalid",
+}
+
+// AlarmDescription returns a short description of an alarm code
+func AlarmDescription(code int) string {
+	if desc, ok := alarmCodes[code]; ok {
+		return desc
+	}
+	return "Unknown alarm"
+}
+
+// ErrorDescription returns a short description of an error code
+func ErrorDescription(code int) string {
+	if desc, ok := errorCodes[code]; ok {
+		return desc
+	}
+	return "Unknown error"
+}
+
+// FormatAlarm formats an alarm for display, e.g. "ALARM 2: Soft limit"
+func FormatAlarm(code int) string {
+	return fmt.Sprintf("ALARM %d: %s", code, AlarmDescription(code))
+}
+
+// FormatError formats an error for display, e.g. "ERROR 9: G-code lock"
+func FormatError(code int) string {
+	return fmt.Sprintf("ERROR %d: %s", code, ErrorDescription(code))
+}
@@ -0,0 +1,317 @@
+package fluidnc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Grbl real-time commands. These are single bytes acted on immediately by
+// the controller and are not acknowledged.
+const (
+	RealtimeReset          byte = 0x18
+	RealtimeStatus         byte = '?'
+	RealtimeCycleStart     byte = '~'
+	RealtimeFeedHold       byte = '!'
+	RealtimeSafetyDoor     byte = 0x84
+	RealtimeJogCancel      byte = 0x85
+	RealtimeFeedReset      byte = 0x90
+	RealtimeFeedPlus10     byte = 0x91
+	RealtimeFeedMinus10    byte = 0x92
+	RealtimeFeedPlus1      byte = 0x93
+	RealtimeFeedMinus1     byte = 0x94
+	RealtimeRapidReset     byte = 0x95
+	RealtimeRapid50        byte = 0x96
+	RealtimeRapid25        byte = 0x97
+	RealtimeSpindleReset   byte = 0x99
+	RealtimeSpindlePlus10  byte = 0x9A
+	RealtimeSpindleMinus10 byte = 0x9B
+	RealtimeSpindlePlus1   byte = 0x9C
+	RealtimeSpindleMinus1  byte = 0x9D
+	RealtimeSpindleStop    byte = 0x9E
+	RealtimeFloodToggle    byte = 0xA0
+	RealtimeMistToggle     byte = 0xA1
+)
+
+const (
+	// defaultCommandTimeout applies to commands sent without a deadline
+	defaultCommandTimeout = 5 * time.Second
+	// queueRetryInterval is how often a command retries a full write queue
+	queueRetryInterval = 10 * time.Millisecond
+)
+
+var (
+	// ErrNotConnected is returned when a command is sent without a connection
+	ErrNotConnected = errors.New("fluidnc: not connected")
+	// ErrTimeout is returned when a command is not acknowledged in time
+	ErrTimeout = errors.New("fluidnc: command timed out")
+	// ErrConnectionLost is returned when the connection drops before a
+	// command is acknowledged
+	ErrConnectionLost = errors.New("fluidnc: connection lost")
+	// ErrReset is returned for commands discarded by a soft reset
+	ErrReset = errors.New("fluidnc: controller reset")
+)
+
+// CommandError is returned when the controller rejects a command with error:n
+type CommandError struct {
+	Command string
+	Code    int
+	// Text is the raw reply when its code is not a number
+	Text string
+}
+
+// Error implements the error interface
+func (e *CommandError) Error() string {
+	if e.Text != "" {
+		return fmt.Sprintf("fluidnc: %s: error:%s", e.Command, e.Text)
+	}
+	return fmt.Sprintf("fluidnc: %s: %s", e.Command, FormatError(e.Code))
+}
+
+// pendingCommand is a line command waiting for ok or error:n. Grbl answers
+// line commands strictly in order, so the oldest pending command owns the
+// next response.
+type pendingCommand struct {
+	line  string
+	reply chan error
+}
+
+// SendRealtime sends a single real-time command byte. Real-time bytes have
+// their own queue and are written ahead of pending line commands.
+func (c *Client) SendRealtime(cmd byte) error {
+	if !c.Connected() {
+		return ErrNotConnected
+	}
+
+	select {
+	case c.realtimeChan <- cmd:
+		return nil
+	default:
+		return fmt.Errorf("fluidnc: real-time queue full")
+	}
+}
+
+// SendCommand sends a line command and waits for the controller to
+// acknowledge it. If ctx has no deadline a default timeout applies.
+func (c *Client) SendCommand(ctx context.Context, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return fmt.Errorf("fluidnc: empty command")
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultCommandTimeout)
+		defer cancel()
+	}
+
+	cmd := &pendingCommand{line: line, reply: make(chan error, 1)}
+
+	// Queue and write under the same lock so responses stay in order, but
+	// never wait for queue space with the lock held
+	for {
+		queued, err := c.enqueue(cmd)
+		if err != nil {
+			return err
+		}
+		if queued {
+			break
+		}
+
+		select {
+		case <-time.After(queueRetryInterval):
+		case <-ctx.Done():
+			// Never written, so no response will come for it
+			return ErrTimeout
+		}
+	}
+
+	select {
+	case err := <-cmd.reply:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+// enqueue adds a command to the pending list and the write queue, or
+// reports false if the write queue is full
+func (c *Client) enqueue(cmd *pendingCommand) (bool, error) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	if !c.Connected() {
+		return false, ErrNotConnected
+	}
+	select {
+	case c.writeChan <- []byte(cmd.line + "\n"):
+		c.pending = append(c.pending, cmd)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// FeedHold pauses motion
+func (c *Client) FeedHold() error {
+	return c.SendRealtime(RealtimeFeedHold)
+}
+
+// CycleStart resumes motion after a feed hold
+func (c *Client) CycleStart() error {
+	return c.SendRealtime(RealtimeCycleStart)
+}
+
+// SoftReset resets the controller, aborting any motion. Commands waiting
+// for acknowledgement are discarded by the controller and fail with ErrReset.
+func (c *Client) SoftReset() error {
+	if err := c.SendRealtime(RealtimeReset); err != nil {
+		return err
+	}
+	// The reset overtakes queued lines, which must not run after it
+	c.dropWrites()
+	c.failPending(ErrReset)
+	return nil
+}
+
+// Unlock clears an alarm lock with $X
+func (c *Client) Unlock(ctx context.Context) error {
+	return c.SendCommand(ctx, "$X")
+}
+
+// Home runs the homing cycle with $H
+func (c *Client) Home(ctx context.Context) error {
+	return c.SendCommand(ctx, "$H")
+}
+
+// AdjustFeedOverride changes the feed override by delta percent in steps
+// of 10 and 1; 0 resets it to 100%
+func (c *Client) AdjustFeedOverride(delta int) error {
+	return c.adjustOverride(delta, RealtimeFeedReset,
+		RealtimeFeedPlus10, RealtimeFeedMinus10, RealtimeFeedPlus1, RealtimeFeedMinus1)
+}
+
+// AdjustSpindleOverride changes the spindle override by delta percent in
+// steps of 10 and 1; 0 resets it to 100%
+func (c *Client) AdjustSpindleOverride(delta int) error {
+	return c.adjustOverride(delta, RealtimeSpindleReset,
+		RealtimeSpindlePlus10, RealtimeSpindleMinus10, RealtimeSpindlePlus1, RealtimeSpindleMinus1)
+}
+
+// SetRapidOverride sets the rapid override to 100, 50 or 25 percent
+func (c *Client) SetRapidOverride(percent int) error {
+	switch percent {
+	case 100:
+		return c.SendRealtime(RealtimeRapidReset)
+	case 50:
+		return c.SendRealtime(RealtimeRapid50)
+	case 25:
+		return c.SendRealtime(RealtimeRapid25)
+	default:
+		return fmt.Errorf("fluidnc: invalid rapid override %d%%", percent)
+	}
+}
+
+// adjustOverride sends the real-time bytes that move an override by delta
+func (c *Client) adjustOverride(delta int, reset, plus10, minus10, plus1, minus1 byte) error {
+	if delta == 0 {
+		return c.SendRealtime(reset)
+	}
+
+	coarse, fine := plus10, plus1
+	if delta < 0 {
+		delta = -delta
+		coarse, fine = minus10, minus1
+	}
+
+	for ; delta >= 10; delta -= 10 {
+		if err := c.SendRealtime(coarse); err != nil {
+			return err
+		}
+	}
+	for ; delta > 0; delta-- {
+		if err := c.SendRealtime(fine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePending completes the oldest pending command with the response
+func (c *Client) resolvePending(event Event) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	if len(c.pending) == 0 {
+		return
+	}
+	cmd := c.pending[0]
+	c.pending = c.pending[1:]
+
+	if cmd.reply == nil {
+		return
+	}
+	if event.Kind == EventError {
+		// Error codes start at 1; 0 means the code did not parse
+		cmdErr := &CommandError{Command: cmd.line, Code: event.Code}
+		if event.Code == 0 {
+			cmdErr.Text = event.Text
+		}
+		cmd.reply <- cmdErr
+	} else {
+		cmd.reply <- nil
+	}
+}
+
+// failPending fails every pending command
+func (c *Client) failPending(err error) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	for _, cmd := range c.pending {
+		if cmd.reply != nil {
+			cmd.reply <- err
+		}
+	}
+	c.pending = nil
+}
+
+// dropWrites discards writes queued for a connection that has gone away
+func (c *Client) dropWrites() {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	for {
+		select {
+		case <-c.writeChan:
+		default:
+			return
+		}
+	}
+}
+
+// dropRealtime discards real-time bytes queued for a connection that has
+// gone away
+func (c *Client) dropRealtime() {
+	for {
+		select {
+		case <-c.realtimeChan:
+		default:
+			return
+		}
+	}
+}
+
+// queueStartup records the startup queries as pending commands so their
+// responses are not mistaken for replies to user commands
+func (c *Client) queueStartup(cmds []*pendingCommand) {
+	c.cmdMu.Lock()
+	defer c.cmdMu.Unlock()
+
+	c.pending = append(c.pending, cmds...)
+}
@@ -0,0 +1,258 @@
+package fluidnc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// fakeController is a telnet controller that answers line commands from a
+// table and records real-time bytes
+type fakeController struct {
+	listener net.Listener
+	replies  map[string]string
+	realtime chan byte
+	polls    int32
+}
+
+// newFakeController starts a fake controller on a local port
+func newFakeController(t *testing.T, replies map[string]string) *fakeController {
+	t.Helper()
+	return newFakeControllerOn(t, "127.0.0.1:0", replies)
+}
+
+// newFakeControllerOn starts a fake controller listening on address
+func newFakeControllerOn(t *testing.T, address string, replies map[string]string) *fakeController {
+	t.Helper()
+
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replies == nil {
+		replies = make(map[string]string)
+	}
+	if _, ok := replies[probeBuild]; !ok {
+		replies[probeBuild] = "[VER:3.7 FluidNC v3.7.8:]\r\n[OPT:PHS,35,254]\r\nok"
+	}
+
+	f := &fakeController{listener: l, replies: replies, realtime: make(chan byte, 32)}
+	go f.serve()
+	return f
+}
+
+// serve handles a single connection
+func (f *fakeController) serve() {
+	conn, err := f.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return
+		}
+
+		switch {
+		case b == '?':
+			atomic.AddInt32(&f.polls, 1)
+			conn.Write([]byte("<Idle|MPos:0,0,0|FS:0,0>\r\n"))
+		case b == '\n':
+			cmd := strings.TrimSpace(string(line))
+			line = line[:0]
+			if reply, ok := f.replies[cmd]; ok {
+				if reply != "" {
+					conn.Write([]byte(reply + "\r\n"))
+				}
+			} else {
+				conn.Write([]byte("ok\r\n"))
+			}
+		case b == '!' || b == '~' || b == 0x18 || b >= 0x80:
+			f.realtime <- b
+		default:
+			line = append(line, b)
+		}
+	}
+}
+
+// config returns a client configuration for the fake controller
+func (f *fakeController) config() types.FluidNCConfig {
+	return types.FluidNCConfig{
+		Host:           "127.0.0.1",
+		Port:           f.listener.Addr().(*net.TCPAddr).Port,
+		Transport:      TransportTelnet,
+		StatusInterval: 0.02,
+	}
+}
+
+// connectClient connects a client and waits for the connection
+func connectClient(t *testing.T, config types.FluidNCConfig) *Client {
+	t.Helper()
+
+	client := NewClient(config)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	waitState(t, client, types.ConnectionConnected)
+	return client
+}
+
+// TestSendCommand tests ok/error correlation and timeouts
+func TestSendCommand(t *testing.T) {
+	f := newFakeController(t, map[string]string{
+		"$X":   "[MSG:Caution: Unlocked]\r\nok",
+		"G1":   "error:22",
+		"G99":  "error:Bad command",
+		"hang": "",
+	})
+	defer f.listener.Close()
+
+	client := connectClient(t, f.config())
+	defer client.Close()
+
+	ctx := context.Background()
+	if err := client.Unlock(ctx); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+
+	err := client.SendCommand(ctx, "G1")
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Code != 22 {
+		t.Errorf("SendCommand(G1) error = %v, want CommandError 22", err)
+	}
+
+	// A reply without a numeric code still answers its command, so the
+	// next ok goes to the next command
+	err = client.SendCommand(ctx, "G99")
+	if !errors.As(err, &cmdErr) || cmdErr.Text != "Bad command" {
+		t.Errorf("SendCommand(G99) error = %v, want CommandError with the raw text", err)
+	}
+	if err := client.Unlock(ctx); err != nil {
+		t.Errorf("Unlock() after a garbled error = %v", err)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := client.SendCommand(tctx, "hang"); !errors.Is(err, ErrTimeout) {
+		t.Errorf("SendCommand(hang) error = %v, want ErrTimeout", err)
+	}
+}
+
+// TestSendRealtime tests real-time commands and overrides
+func TestSendRealtime(t *testing.T) {
+	f := newFakeController(t, nil)
+	defer f.listener.Close()
+
+	client := connectClient(t, f.config())
+	defer client.Close()
+
+	if err := client.FeedHold(); err != nil {
+		t.Fatalf("FeedHold() error = %v", err)
+	}
+	if err := client.CycleStart(); err != nil {
+		t.Fatalf("CycleStart() error = %v", err)
+	}
+	if err := client.AdjustFeedOverride(-12); err != nil {
+		t.Fatalf("AdjustFeedOverride() error = %v", err)
+	}
+	if err := client.SetRapidOverride(75); err == nil {
+		t.Error("SetRapidOverride(75) did not return error")
+	}
+
+	want := []byte{RealtimeFeedHold, RealtimeCycleStart, RealtimeFeedMinus10, RealtimeFeedMinus1, RealtimeFeedMinus1}
+	for _, w := range want {
+		select {
+		case b := <-f.realtime:
+			if b != w {
+				t.Errorf("real-time byte = %#x, want %#x", b, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("real-time byte %#x not received", w)
+		}
+	}
+}
+
+// recordingTransport is a Transport that records writes and never reads
+type recordingTransport struct {
+	writes chan []byte
+	closed chan struct{}
+}
+
+// ReadLine blocks until the transport is closed
+func (r *recordingTransport) ReadLine() (string, error) {
+	<-r.closed
+	return "", net.ErrClosed
+}
+
+// Write records data
+func (r *recordingTransport) Write(data []byte) error {
+	r.writes <- data
+	return nil
+}
+
+// Ping does nothing
+func (r *recordingTransport) Ping() error { return nil }
+
+// Close does nothing
+func (r *recordingTransport) Close() error { return nil }
+
+// TestFeedHoldWithFullQueue tests that real-time bytes are neither blocked
+// nor delayed by a full command queue
+func TestFeedHoldWithFullQueue(t *testing.T) {
+	client := NewClient(types.FluidNCConfig{StatusInterval: 60})
+	client.setState(types.ConnectionConnected)
+	for i := 0; i < cap(client.writeChan); i++ {
+		client.writeChan <- []byte("G4 P0\n")
+	}
+
+	// A command waiting for queue space must not hold up the feed hold
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go client.SendCommand(ctx, "G0 X1")
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if err := client.FeedHold(); err != nil {
+		t.Fatalf("FeedHold() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("FeedHold() took %v", elapsed)
+	}
+
+	conn := &recordingTransport{writes: make(chan []byte, 128), closed: make(chan struct{})}
+	stop := make(chan struct{})
+	defer close(stop)
+	go client.writePump(ctx, conn, stop, nil, nil)
+
+	select {
+	case data := <-conn.writes:
+		if string(data) != string([]byte{RealtimeFeedHold}) {
+			t.Errorf("first write = %q, want feed hold", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing written")
+	}
+}
+
+// TestSendCommandNotConnected tests commands without a connection
+func TestSendCommandNotConnected(t *testing.T) {
+	client := NewClient(types.FluidNCConfig{Host: "127.0.0.1", Port: 1})
+	if err := client.SendCommand(context.Background(), "$X"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("SendCommand() error = %v, want ErrNotConnected", err)
+	}
+	if err := client.FeedHold(); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("FeedHold() error = %v, want ErrNotConnected", err)
+	}
+}
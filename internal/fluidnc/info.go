@@ -0,0 +1,199 @@
+package fluidnc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// Probe queries sent on every connect. [ESP800] is answered by FluidNC (and
+// other ESP32 ports) with a '#' separated key:value list; $I is answered by
+// every Grbl derivative with [VER:...] and [OPT:...].
+const (
+	probeESP800 = "[ESP800]"
+	probeBuild  = "$I"
+)
+
+// ErrUnrecognised is returned by Identify when the device never answers
+// like a Grbl controller
+var ErrUnrecognised = errors.New("no FluidNC or Grbl response")
+
+// Identify connects to a device, probes it and returns what it reports. It
+// returns once the [ESP800] probe is answered or rejected; a device that
+// only answers status reports is identified as Grbl.
+func Identify(ctx context.Context, config types.FluidNCConfig) (types.ControllerInfo, error) {
+	var info types.ControllerInfo
+
+	if err := validateConfig(config); err != nil {
+		return info, err
+	}
+	conn, err := dialTransport(ctx, config)
+	if err != nil {
+		return info, err
+	}
+	defer conn.Close()
+
+	// Unblock ReadLine when the context ends
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if err := conn.Write([]byte(probeESP800 + "\n")); err != nil {
+		return info, err
+	}
+	if err := conn.Write([]byte{RealtimeStatus}); err != nil {
+		return info, err
+	}
+
+	var p statusParser
+	recognised := false
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			if ctx.Err() == nil {
+				return info, err
+			}
+			break
+		}
+
+		event, err := p.parseLine(line)
+		if err != nil {
+			continue
+		}
+
+		switch event.Kind {
+		case EventStatus:
+			recognised = true
+		case EventOK, EventError:
+			// Only a reply after something Grbl-like proves the protocol
+			if recognised || info.Firmware != "" {
+				return identified(info), nil
+			}
+		default:
+			if updateInfo(&info, event) {
+				recognised = true
+			}
+		}
+	}
+
+	if !recognised {
+		return info, ErrUnrecognised
+	}
+	return identified(info), nil
+}
+
+// identified fills in the firmware of a controller that answered status
+// reports but nothing else
+func identified(info types.ControllerInfo) types.ControllerInfo {
+	if info.Firmware == "" {
+		info.Firmware = types.FirmwareGrbl
+	}
+	return info
+}
+
+// ParseESP800 parses an [ESP800] response line and reports whether it was one
+func ParseESP800(line string) (types.ControllerInfo, bool) {
+	var info types.ControllerInfo
+	if !strings.HasPrefix(line, "FW version:") {
+		return info, false
+	}
+	parseESP800(&info, line)
+	return info, true
+}
+
+// updateInfo applies a probe response or banner to the controller info and
+// reports whether anything was recognised
+func updateInfo(info *types.ControllerInfo, event Event) bool {
+	switch event.Kind {
+	case EventWelcome:
+		// Grbl 3.7 [FluidNC v3.7.8 (wifi) '$' for help]
+		// Grbl 1.1h ['$' for help]
+		fields := strings.Fields(event.Raw)
+		if i := indexOf(fields, "[FluidNC"); i >= 0 && i+1 < len(fields) {
+			info.Firmware = types.FirmwareFluidNC
+			info.Version = fields[i+1]
+		} else if info.Firmware == "" && len(fields) > 1 {
+			info.Firmware = types.FirmwareGrbl
+			info.Version = fields[1]
+		}
+		return true
+
+	case EventVersion:
+		// [VER:3.7 FluidNC v3.7.8:] or [VER:1.1h.20190825:name]
+		version, _, _ := strings.Cut(event.Text, ":")
+		fields := strings.Fields(version)
+		if i := indexOf(fields, types.FirmwareFluidNC); i >= 0 && i+1 < len(fields) {
+			info.Firmware = types.FirmwareFluidNC
+			info.Version = fields[i+1]
+		} else if len(fields) > 0 {
+			info.Firmware = types.FirmwareGrbl
+			info.Version = fields[0]
+		}
+		return true
+
+	case EventOptions:
+		// [OPT:PHS,35,254]
+		fields := strings.Split(event.Text, ",")
+		info.Options = fields[0]
+		if len(fields) >= 3 {
+			info.PlannerBlocks, _ = strconv.Atoi(fields[1])
+			info.RXBufferSize, _ = strconv.Atoi(fields[2])
+		}
+		return true
+
+	case EventOther:
+		if !strings.HasPrefix(event.Raw, "FW version:") {
+			return false
+		}
+		parseESP800(info, event.Raw)
+		return true
+	}
+
+	return false
+}
+
+// parseESP800 parses the [ESP800] response, e.g.
+// FW version: FluidNC v3.7.8 (wifi) # FW target:grbl-embedded # hostname:cnc # axis:3
+func parseESP800(info *types.ControllerInfo, line string) {
+	for _, part := range strings.Split(line, "#") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch strings.TrimSpace(key) {
+		case "FW version":
+			fields := strings.Fields(value)
+			if len(fields) >= 2 {
+				info.Firmware = fields[0]
+				info.Version = fields[1]
+			}
+		case "hostname":
+			info.Hostname = value
+		case "axis":
+			if axes, err := strconv.Atoi(value); err == nil {
+				info.Axes = axes
+			}
+		}
+	}
+}
+
+// indexOf returns the index of s in fields, or -1
+func indexOf(fields []string, s string) int {
+	for i, f := range fields {
+		if f == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,146 @@
+package fluidnc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestUpdateInfo tests parsing of probe responses into ControllerInfo
+func TestUpdateInfo(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  types.ControllerInfo
+	}{
+		{
+			name: "FluidNC",
+			lines: []string{
+				"Grbl 3.7 [FluidNC v3.7.8 (wifi) '$' for help]",
+				"FW version: FluidNC v3.7.8 (wifi) # FW target:grbl-embedded  # FW HW:Direct SD  # primary sd:/sd # secondary sd:none  # authentication:no # webcommunication: Sync: 81 # hostname:shopbot # axis:4",
+				"[VER:3.7 FluidNC v3.7.8:]",
+				"[OPT:PHS,35,254]",
+			},
+			want: types.ControllerInfo{
+				Firmware:      types.FirmwareFluidNC,
+				Version:       "v3.7.8",
+				Hostname:      "shopbot",
+				Axes:          4,
+				Options:       "PHS",
+				PlannerBlocks: 35,
+				RXBufferSize:  254,
+			},
+		},
+		{
+			name: "classic Grbl",
+			lines: []string{
+				"Grbl 1.1h ['$' for help]",
+				"error:1",
+				"[VER:1.1h.20190825:]",
+				"[OPT:V,15,128]",
+			},
+			want: types.ControllerInfo{
+				Firmware:      types.FirmwareGrbl,
+				Version:       "1.1h.20190825",
+				Options:       "V",
+				PlannerBlocks: 15,
+				RXBufferSize:  128,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p statusParser
+			var info types.ControllerInfo
+			for _, line := range tt.lines {
+				event, err := p.parseLine(line)
+				if err != nil {
+					t.Fatalf("parseLine(%q) error = %v", line, err)
+				}
+				updateInfo(&info, event)
+			}
+			if info != tt.want {
+				t.Errorf("info = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientInfo tests that the client probes the controller on connect
+func TestClientInfo(t *testing.T) {
+	f := newFakeController(t, map[string]string{
+		probeESP800: "FW version: FluidNC v3.7.8 # hostname:router # axis:3\r\nok",
+	})
+	defer f.listener.Close()
+
+	client := connectClient(t, f.config())
+	defer client.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		info := client.Info()
+		if info.IsFluidNC() && info.Hostname == "router" && info.PlannerBlocks == 35 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Info() = %+v", client.Info())
+}
+
+// TestWebUIControlFiltered tests that WebSocket control frames are dropped
+func TestWebUIControlFiltered(t *testing.T) {
+	for _, line := range []string{"CURRENT_ID:0", "ACTIVE_ID:0", "PING:60000:60000"} {
+		if !isWebUIControl(line) {
+			t.Errorf("isWebUIControl(%q) = false", line)
+		}
+	}
+	if isWebUIControl("<Idle|MPos:0,0,0>") {
+		t.Error("isWebUIControl() dropped a status report")
+	}
+}
+
+// TestIdentify tests fingerprinting FluidNC and classic Grbl controllers
+func TestIdentify(t *testing.T) {
+	tests := []struct {
+		name   string
+		esp800 string
+		want   types.ControllerInfo
+	}{
+		{
+			name:   "FluidNC",
+			esp800: "FW version: FluidNC v3.7.8 (wifi) # hostname:router # axis:3\r\nok",
+			want: types.ControllerInfo{
+				Firmware: types.FirmwareFluidNC,
+				Version:  "v3.7.8",
+				Hostname: "router",
+				Axes:     3,
+			},
+		},
+		{
+			name:   "status reports only",
+			esp800: "error:3",
+			want:   types.ControllerInfo{Firmware: types.FirmwareGrbl},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeController(t, map[string]string{probeESP800: tt.esp800})
+			defer f.listener.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			info, err := Identify(ctx, f.config())
+			if err != nil {
+				t.Fatalf("Identify() error = %v", err)
+			}
+			if info != tt.want {
+				t.Errorf("Identify() = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
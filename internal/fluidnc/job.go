@@ -0,0 +1,108 @@
+package fluidnc
+
+import (
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// minETAProgress is the progress needed before an ETA is estimated
+const minETAProgress = 0.5
+
+// JobTracker follows a job from the first Run state until the machine is
+// idle again, using the SD field of status reports for progress.
+//
+// Timing is taken from MachineStatus.LastUpdated so that it is
+// deterministic for a given sequence of reports. Time spent in Hold or Door
+// is left out of the rate used for the ETA.
+type JobTracker struct {
+	job          types.JobStatus
+	startPercent float64
+	haveStart    bool
+	paused       time.Duration
+	pausedAt     time.Time
+}
+
+// Job returns the current or last job
+func (t *JobTracker) Job() types.JobStatus {
+	return t.job
+}
+
+// Update advances the tracker with a new status report
+func (t *JobTracker) Update(status types.MachineStatus) types.JobStatus {
+	now := status.LastUpdated
+
+	running := status.SD.Active || status.State == types.StateRun
+	holding := status.State == types.StateHold || status.State == types.StateDoor
+	if t.job.Active && holding {
+		// A paused job is still running
+		running = true
+	}
+
+	switch {
+	case running && !t.job.Active:
+		// A new job has started
+		t.job = types.JobStatus{
+			Active:  true,
+			File:    status.SD.File,
+			Started: now,
+		}
+		t.haveStart = false
+		t.paused = 0
+		t.pausedAt = time.Time{}
+	case !running && t.job.Active:
+		// The job has finished; keep the final figures for display
+		t.job.Active = false
+		t.job.Elapsed = now.Sub(t.job.Started)
+		t.job.Remaining = 0
+		if t.haveStart && status.State == types.StateIdle {
+			// The SD field disappears before the last report reaches 100%
+			t.job.Percent = 100
+		}
+		return t.job
+	case !running:
+		return t.job
+	}
+
+	t.job.Elapsed = now.Sub(t.job.Started)
+
+	switch {
+	case holding && t.pausedAt.IsZero():
+		t.pausedAt = now
+	case !holding && !t.pausedAt.IsZero():
+		t.paused += now.Sub(t.pausedAt)
+		t.pausedAt = time.Time{}
+	}
+	active := t.job.Elapsed - t.paused
+	if !t.pausedAt.IsZero() {
+		active -= now.Sub(t.pausedAt)
+	}
+
+	if status.SD.Active {
+		if status.SD.File != "" {
+			t.job.File = status.SD.File
+		}
+		t.job.Percent = status.SD.Percent
+
+		// Progress may already be under way if we connected mid-job, so
+		// the rate is measured from the first percentage seen
+		if !t.haveStart {
+			t.startPercent = status.SD.Percent
+			t.haveStart = true
+		}
+		t.job.Remaining = estimateRemaining(active, t.startPercent, t.job.Percent)
+	}
+
+	return t.job
+}
+
+// estimateRemaining extrapolates the time left from the progress made so far
+func estimateRemaining(elapsed time.Duration, from, to float64) time.Duration {
+	done := to - from
+	if done < minETAProgress || elapsed <= 0 {
+		return 0
+	}
+
+	rate := done / elapsed.Seconds()
+	return time.Duration((100 - to) / rate * float64(time.Second)).Round(time.Second)
+}
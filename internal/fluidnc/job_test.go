@@ -0,0 +1,122 @@
+package fluidnc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestJobTracker tests job start, progress, ETA and completion
+func TestJobTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := func(state types.MachineState, offset time.Duration, sd *types.SDStatus) types.MachineStatus {
+		s := types.MachineStatus{State: state, LastUpdated: start.Add(offset)}
+		if sd != nil {
+			s.SD = *sd
+		}
+		return s
+	}
+
+	var tracker JobTracker
+
+	job := tracker.Update(report(types.StateIdle, 0, nil))
+	if job.Active {
+		t.Fatal("job active while idle")
+	}
+
+	job = tracker.Update(report(types.StateRun, 0, &types.SDStatus{Active: true, Percent: 0, File: "/sd/part.nc"}))
+	if !job.Active || job.File != "/sd/part.nc" || !job.Started.Equal(start) {
+		t.Fatalf("job = %+v, want started", job)
+	}
+
+	job = tracker.Update(report(types.StateRun, time.Minute, &types.SDStatus{Active: true, Percent: 25}))
+	if job.Elapsed != time.Minute || job.Percent != 25 {
+		t.Errorf("job = %+v", job)
+	}
+	if job.Remaining != 3*time.Minute {
+		t.Errorf("Remaining = %v, want 3m", job.Remaining)
+	}
+
+	// A feed hold keeps the job running
+	job = tracker.Update(report(types.StateHold, 90*time.Second, nil))
+	if !job.Active {
+		t.Error("job stopped during hold")
+	}
+
+	job = tracker.Update(report(types.StateIdle, 4*time.Minute, nil))
+	if job.Active || job.Percent != 100 || job.Elapsed != 4*time.Minute || job.Remaining != 0 {
+		t.Errorf("job = %+v, want finished", job)
+	}
+}
+
+// TestJobTrackerMidJob tests the ETA when connecting to a job in progress
+func TestJobTrackerMidJob(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var tracker JobTracker
+	tracker.Update(types.MachineStatus{
+		State:       types.StateRun,
+		SD:          types.SDStatus{Active: true, Percent: 50, File: "a.nc"},
+		LastUpdated: start,
+	})
+	job := tracker.Update(types.MachineStatus{
+		State:       types.StateRun,
+		SD:          types.SDStatus{Active: true, Percent: 60, File: "a.nc"},
+		LastUpdated: start.Add(time.Minute),
+	})
+	if job.Remaining != 4*time.Minute {
+		t.Errorf("Remaining = %v, want 4m", job.Remaining)
+	}
+}
+
+// TestJobTrackerPause tests that time in Hold and Door does not stretch the ETA
+func TestJobTrackerPause(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := func(state types.MachineState, offset time.Duration, percent float64) types.MachineStatus {
+		return types.MachineStatus{
+			State:       state,
+			SD:          types.SDStatus{Active: true, Percent: percent, File: "a.nc"},
+			LastUpdated: start.Add(offset),
+		}
+	}
+
+	var tracker JobTracker
+	tracker.Update(report(types.StateRun, 0, 0))
+	tracker.Update(report(types.StateRun, time.Minute, 25))
+
+	job := tracker.Update(report(types.StateHold, time.Minute, 25))
+	if job.Remaining != 3*time.Minute {
+		t.Errorf("Remaining in hold = %v, want 3m", job.Remaining)
+	}
+	job = tracker.Update(report(types.StateDoor, 4*time.Minute, 25))
+	if job.Remaining != 3*time.Minute {
+		t.Errorf("Remaining at door = %v, want 3m", job.Remaining)
+	}
+
+	// Four minutes paused, then one more minute of cutting
+	tracker.Update(report(types.StateRun, 5*time.Minute, 25))
+	job = tracker.Update(report(types.StateRun, 6*time.Minute, 50))
+	if job.Elapsed != 6*time.Minute {
+		t.Errorf("Elapsed = %v, want 6m", job.Elapsed)
+	}
+	if job.Remaining != 2*time.Minute {
+		t.Errorf("Remaining after resume = %v, want 2m", job.Remaining)
+	}
+}
+
+// TestParseSDField tests parsing of the SD status field
+func TestParseSDField(t *testing.T) {
+	var p statusParser
+	status, err := p.parseStatusMessage("<Run|MPos:0,0,0|FS:100,0|SD:42.50,/sd/part.nc>")
+	if err != nil {
+		t.Fatalf("parseStatusMessage() error = %v", err)
+	}
+	if !status.SD.Active || status.SD.Percent != 42.5 || status.SD.File != "/sd/part.nc" {
+		t.Errorf("SD = %+v", status.SD)
+	}
+
+	if _, err := p.parseStatusMessage("<Run|MPos:0,0,0|SD:abc,x.nc>"); err == nil {
+		t.Error("parseStatusMessage() with bad SD field did not return error")
+	}
+}
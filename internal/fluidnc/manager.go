@@ -0,0 +1,205 @@
+package fluidnc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// Manager runs one client per machine and keeps the latest display data
+// for each of them
+type Manager struct {
+	machines []*machine
+	mu       sync.RWMutex
+	changed  chan struct{}
+}
+
+// machine is a monitored controller and what is known about it
+type machine struct {
+	client *Client
+	data   types.DisplayData
+}
+
+// NewManager creates a manager for the given controllers
+func NewManager(configs []types.FluidNCConfig) (*Manager, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no machines configured")
+	}
+
+	m := &Manager{changed: make(chan struct{}, 1)}
+	seen := make(map[string]bool)
+	for _, config := range configs {
+		if err := validateConfig(config); err != nil {
+			return nil, err
+		}
+
+		name := machineName(config)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate machine name %q", name)
+		}
+		seen[name] = true
+
+		m.machines = append(m.machines, &machine{
+			client: NewClient(config),
+			data: types.DisplayData{
+				Name:       name,
+				IPAddress:  config.Host,
+				Connection: types.ConnectionDisconnected,
+			},
+		})
+	}
+
+	return m, nil
+}
+
+// machineName returns the display name of a controller
+func machineName(config types.FluidNCConfig) string {
+	switch {
+	case config.Name != "":
+		return config.Name
+	case config.Host != "":
+		return config.Host
+	default:
+		return config.Device
+	}
+}
+
+// Start connects to every machine
+func (m *Manager) Start(ctx context.Context) error {
+	for _, mc := range m.machines {
+		if err := mc.client.Connect(ctx); err != nil {
+			return fmt.Errorf("%s: %w", mc.data.Name, err)
+		}
+		go m.watch(ctx, mc)
+	}
+	return nil
+}
+
+// Close disconnects from every machine
+func (m *Manager) Close() {
+	for _, mc := range m.machines {
+		mc.client.Close()
+	}
+}
+
+// Machines returns a snapshot of the display data of every machine, in
+// configuration order
+func (m *Manager) Machines() []types.DisplayData {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data := make([]types.DisplayData, len(m.machines))
+	for i, mc := range m.machines {
+		data[i] = mc.data
+	}
+	return data
+}
+
+// Client returns the client of the named machine, or nil
+func (m *Manager) Client(name string) *Client {
+	for _, mc := range m.machines {
+		if mc.data.Name == name {
+			return mc.client
+		}
+	}
+	return nil
+}
+
+// Relocate points every machine at oldHost to newHost and returns how many
+// moved
+func (m *Manager) Relocate(oldHost, newHost string) int {
+	moved := 0
+	for _, mc := range m.machines {
+		if mc.client.Host() != oldHost {
+			continue
+		}
+		mc.client.SetHost(newHost)
+		m.update(mc, func(d *types.DisplayData) {
+			d.IPAddress = newHost
+		})
+		moved++
+	}
+	return moved
+}
+
+// Changed returns a channel that is signalled whenever any machine's data
+// changes. Signals are coalesced; call Machines for the current data.
+func (m *Manager) Changed() <-chan struct{} {
+	return m.changed
+}
+
+// watch folds a client's status, connection state and events into its data
+func (m *Manager) watch(ctx context.Context, mc *machine) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-mc.client.done:
+			return
+
+		case status := <-mc.client.Status():
+			m.update(mc, func(d *types.DisplayData) {
+				d.MachineStatus = status
+				d.Job = mc.client.Job()
+				d.Controller = mc.client.Info()
+				if status.State != types.StateAlarm {
+					d.AlarmCode = 0
+				}
+			})
+
+		case state := <-mc.client.State():
+			m.update(mc, func(d *types.DisplayData) {
+				d.Connection = state
+				d.Connected = state == types.ConnectionConnected
+				d.Controller = mc.client.Info()
+			})
+
+		case event := <-mc.client.Events():
+			m.handleEvent(mc, event)
+		}
+	}
+}
+
+// handleEvent folds a controller event into a machine's data. Command
+// replies are returned to their callers and modal state and probe results
+// are not shown, so EventOK, EventError, EventGCode and EventProbe are
+// ignored; status reports arrive on the status channel instead.
+func (m *Manager) handleEvent(mc *machine, event Event) {
+	switch event.Kind {
+	case EventAlarm:
+		m.update(mc, func(d *types.DisplayData) {
+			d.AlarmCode = event.Code
+		})
+	case EventMessage:
+		m.update(mc, func(d *types.DisplayData) {
+			d.Message = event.Text
+		})
+	case EventWelcome, EventVersion, EventOptions, EventOther:
+		// Probe responses and the startup banner identify the controller
+		info := mc.client.Info()
+		m.mu.RLock()
+		changed := info != mc.data.Controller
+		m.mu.RUnlock()
+		if changed {
+			m.update(mc, func(d *types.DisplayData) {
+				d.Controller = info
+			})
+		}
+	}
+}
+
+// update applies a change to a machine's data and signals consumers
+func (m *Manager) update(mc *machine, fn func(*types.DisplayData)) {
+	m.mu.Lock()
+	fn(&mc.data)
+	mc.data.LastUpdated = time.Now()
+	m.mu.Unlock()
+
+	select {
+	case m.changed <- struct{}{}:
+	default:
+	}
+}
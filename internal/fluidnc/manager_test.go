@@ -0,0 +1,151 @@
+package fluidnc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestManager tests monitoring two machines at once
+func TestManager(t *testing.T) {
+	// Reject automatic reports so both clients keep polling
+	router := newFakeController(t, map[string]string{"$Report/Interval=20": "error:3"})
+	defer router.listener.Close()
+	laser := newFakeController(t, map[string]string{"$Report/Interval=20": "error:3"})
+	defer laser.listener.Close()
+
+	routerCfg := router.config()
+	routerCfg.Name = "router"
+	laserCfg := laser.config()
+	laserCfg.Name = "laser"
+
+	m, err := NewManager([]types.FluidNCConfig{routerCfg, laserCfg})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer m.Close()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		machines := m.Machines()
+		if machines[0].Connected && machines[1].Connected &&
+			machines[0].MachineStatus.State == types.StateIdle &&
+			machines[1].MachineStatus.State == types.StateIdle {
+			if machines[0].Name != "router" || machines[1].Name != "laser" {
+				t.Errorf("names = %q, %q", machines[0].Name, machines[1].Name)
+			}
+			break
+		}
+
+		select {
+		case <-m.Changed():
+		case <-deadline:
+			t.Fatalf("machines = %+v", machines)
+		}
+	}
+
+	if m.Client("laser") == nil || m.Client("mill") != nil {
+		t.Error("Client() lookup by name failed")
+	}
+}
+
+// TestManagerControllerInfo tests that probe responses reach the display
+// data of every machine without waiting for a status report
+func TestManagerControllerInfo(t *testing.T) {
+	var configs []types.FluidNCConfig
+	for _, name := range []string{"router", "laser"} {
+		f := newFakeController(t, nil)
+		defer f.listener.Close()
+
+		config := f.config()
+		config.Name = name
+		// Never poll during the test
+		config.StatusInterval = 60
+		configs = append(configs, config)
+	}
+
+	m, err := NewManager(configs)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer m.Close()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		machines := m.Machines()
+		if machines[0].Controller.Version == "v3.7.8" && machines[1].Controller.Version == "v3.7.8" {
+			for _, d := range machines {
+				if !d.MachineStatus.LastUpdated.IsZero() {
+					t.Errorf("%s: unexpected status report", d.Name)
+				}
+			}
+			break
+		}
+
+		select {
+		case <-m.Changed():
+		case <-deadline:
+			t.Fatalf("machines = %+v", machines)
+		}
+	}
+}
+
+// TestNewManagerErrors tests manager configuration errors
+func TestNewManagerErrors(t *testing.T) {
+	if _, err := NewManager(nil); err == nil {
+		t.Error("NewManager(nil) did not return error")
+	}
+
+	dup := types.FluidNCConfig{Host: "10.0.0.5", Port: 81}
+	if _, err := NewManager([]types.FluidNCConfig{dup, dup}); err == nil {
+		t.Error("NewManager() with duplicate machines did not return error")
+	}
+}
+
+// TestManagerRelocate tests following a machine to a new address
+func TestManagerRelocate(t *testing.T) {
+	noAutoReport := map[string]string{"$Report/Interval=20": "error:3"}
+	before := newFakeController(t, noAutoReport)
+	defer before.listener.Close()
+	port := before.listener.Addr().(*net.TCPAddr).Port
+	after := newFakeControllerOn(t, fmt.Sprintf("127.0.0.2:%d", port), noAutoReport)
+	defer after.listener.Close()
+
+	m, err := NewManager([]types.FluidNCConfig{before.config()})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer m.Close()
+
+	if moved := m.Relocate("192.168.1.99", "127.0.0.2"); moved != 0 {
+		t.Errorf("Relocate() of an unknown host moved %d machines", moved)
+	}
+	if moved := m.Relocate("127.0.0.1", "127.0.0.2"); moved != 1 {
+		t.Fatalf("Relocate() moved %d machines, want 1", moved)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&after.polls) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("client did not reconnect to the new address")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := m.Machines()[0].IPAddress; got != "127.0.0.2" {
+		t.Errorf("IPAddress = %q, want 127.0.0.2", got)
+	}
+}
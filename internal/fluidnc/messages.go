@@ -0,0 +1,260 @@
+package fluidnc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// EventKind identifies the kind of line received from the controller
+type EventKind int
+
+const (
+	// EventStatus is a <...> status report
+	EventStatus EventKind = iota
+	// EventOK acknowledges a command
+	EventOK
+	// EventError rejects a command with error:n
+	EventError
+	// EventAlarm reports ALARM:n
+	EventAlarm
+	// EventMessage is a [MSG:...] feedback message
+	EventMessage
+	// EventGCode is a [GC:...] modal state report
+	EventGCode
+	// EventProbe is a [PRB:...] probe result
+	EventProbe
+	// EventVersion is a [VER:...] build info line
+	EventVersion
+	// EventOptions is an [OPT:...] build options line
+	EventOptions
+	// EventWelcome is the Grbl/FluidNC startup banner
+	EventWelcome
+	// EventOther is any other line, e.g. settings or ESP command output
+	EventOther
+)
+
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventStatus:
+		return "status"
+	case EventOK:
+		return "ok"
+	case EventError:
+		return "error"
+	case EventAlarm:
+		return "alarm"
+	case EventMessage:
+		return "message"
+	case EventGCode:
+		return "gcode"
+	case EventProbe:
+		return "probe"
+	case EventVersion:
+		return "version"
+	case EventOptions:
+		return "options"
+	case EventWelcome:
+		return "welcome"
+	default:
+		return "other"
+	}
+}
+
+// GCodeState represents the modal state reported by [GC:...]
+type GCodeState struct {
+	Motion   string // G0, G1, G2, G3, G38.x, G80
+	WCS      string // G54 to G59
+	Plane    string // G17, G18, G19
+	Units    string // G20, G21
+	Distance string // G90, G91
+	FeedMode string // G93, G94
+	Program  string // M0, M1, M2, M30
+	Spindle  string // M3, M4, M5
+	Coolant  []string
+	Tool     int
+	Feed     float64
+	Speed    float64
+}
+
+// ProbeResult represents the result of the last probing cycle
+type ProbeResult struct {
+	Position types.Coordinates
+	Success  bool
+}
+
+// Event represents a classified line received from the controller
+type Event struct {
+	Kind EventKind
+	// Raw is the line as received
+	Raw string
+	// Status is set for EventStatus
+	Status types.MachineStatus
+	// Code is set for EventError and EventAlarm
+	Code int
+	// Text is the payload of messages, build info and other lines
+	Text string
+	// GCode is set for EventGCode
+	GCode GCodeState
+	// Probe is set for EventProbe
+	Probe ProbeResult
+}
+
+// String formats the event for display
+func (e Event) String() string {
+	switch e.Kind {
+	case EventAlarm:
+		return FormatAlarm(e.Code)
+	case EventError:
+		return FormatError(e.Code)
+	case EventMessage:
+		return e.Text
+	default:
+		return e.Raw
+	}
+}
+
+// parseLine classifies a line and parses its payload
+func (p *statusParser) parseLine(line string) (Event, error) {
+	event := Event{Kind: EventOther, Raw: line, Text: line}
+
+	switch {
+	case strings.HasPrefix(line, "<"):
+		status, err := p.parseStatusMessage(line)
+		if err != nil {
+			return event, err
+		}
+		event.Kind = EventStatus
+		event.Status = status
+
+	case line == "ok":
+		event.Kind = EventOK
+
+	case strings.HasPrefix(line, "error:"):
+		event.Kind = EventError
+		event.Text = strings.TrimPrefix(line, "error:")
+		code, err := strconv.Atoi(strings.TrimSpace(event.Text))
+		if err != nil {
+			return event, fmt.Errorf("invalid error code %q", event.Text)
+		}
+		event.Code = code
+
+	case strings.HasPrefix(line, "ALARM:"):
+		event.Kind = EventAlarm
+		event.Text = strings.TrimPrefix(line, "ALARM:")
+		code, err := strconv.Atoi(strings.TrimSpace(event.Text))
+		if err != nil {
+			return event, fmt.Errorf("invalid alarm code %q", event.Text)
+		}
+		event.Code = code
+
+	case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+		return parseBracketed(event, line[1:len(line)-1])
+
+	case strings.HasPrefix(line, "Grbl "):
+		event.Kind = EventWelcome
+	}
+
+	return event, nil
+}
+
+// parseBracketed parses a [KEY:payload] feedback line
+func parseBracketed(event Event, body string) (Event, error) {
+	key, payload, ok := strings.Cut(body, ":")
+	if !ok {
+		return event, nil
+	}
+	event.Text = payload
+
+	switch key {
+	case "MSG":
+		event.Kind = EventMessage
+	case "VER":
+		event.Kind = EventVersion
+	case "OPT":
+		event.Kind = EventOptions
+	case "GC":
+		event.Kind = EventGCode
+		gc, err := parseGCodeState(payload)
+		if err != nil {
+			return event, fmt.Errorf("GC: %w", err)
+		}
+		event.GCode = gc
+	case "PRB":
+		event.Kind = EventProbe
+		pos, result, ok := strings.Cut(payload, ":")
+		if !ok || (result != "0" && result != "1") {
+			return event, fmt.Errorf("invalid probe result %q", payload)
+		}
+		coords, err := parseCoordinates(pos)
+		if err != nil {
+			return event, fmt.Errorf("PRB: %w", err)
+		}
+		event.Probe = ProbeResult{Position: coords, Success: result == "1"}
+	}
+
+	return event, nil
+}
+
+// parseGCodeState parses the words of a [GC:...] report
+func parseGCodeState(payload string) (GCodeState, error) {
+	var gc GCodeState
+
+	for _, word := range strings.Fields(payload) {
+		if len(word) < 2 {
+			return gc, fmt.Errorf("invalid word %q", word)
+		}
+
+		value := word[1:]
+		switch word[0] {
+		case 'G':
+			switch {
+			case value == "0" || value == "1" || value == "2" || value == "3" ||
+				value == "80" || strings.HasPrefix(value, "38."):
+				gc.Motion = word
+			case value >= "54" && value <= "59" && len(value) == 2:
+				gc.WCS = word
+			case value == "17" || value == "18" || value == "19":
+				gc.Plane = word
+			case value == "20" || value == "21":
+				gc.Units = word
+			case value == "90" || value == "91":
+				gc.Distance = word
+			case value == "93" || value == "94":
+				gc.FeedMode = word
+			}
+		case 'M':
+			switch value {
+			case "0", "1", "2", "30":
+				gc.Program = word
+			case "3", "4", "5":
+				gc.Spindle = word
+			case "7", "8", "9":
+				gc.Coolant = append(gc.Coolant, word)
+			}
+		case 'T':
+			tool, err := strconv.Atoi(value)
+			if err != nil {
+				return gc, fmt.Errorf("invalid tool %q", word)
+			}
+			gc.Tool = tool
+		case 'F':
+			feed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return gc, fmt.Errorf("invalid feed %q", word)
+			}
+			gc.Feed = feed
+		case 'S':
+			speed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return gc, fmt.Errorf("invalid speed %q", word)
+			}
+			gc.Speed = speed
+		}
+	}
+
+	return gc, nil
+}
@@ -0,0 +1,96 @@
+package fluidnc
+
+import (
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestParseLine tests classification of controller output
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		kind    EventKind
+		check   func(t *testing.T, e Event)
+		wantErr bool
+	}{
+		{line: "<Idle|MPos:0,0,0|FS:0,0>", kind: EventStatus, check: func(t *testing.T, e Event) {
+			if e.Status.State != types.StateIdle {
+				t.Errorf("State = %s, want Idle", e.Status.State)
+			}
+		}},
+		{line: "ok", kind: EventOK},
+		{line: "error:9", kind: EventError, check: func(t *testing.T, e Event) {
+			if e.Code != 9 || e.String() != "ERROR 9: G-code lock" {
+				t.Errorf("error = %d %q", e.Code, e.String())
+			}
+		}},
+		{line: "ALARM:2", kind: EventAlarm, check: func(t *testing.T, e Event) {
+			if e.String() != "ALARM 2: Soft limit" {
+				t.Errorf("String() = %q", e.String())
+			}
+		}},
+		{line: "[MSG:INFO: Homing done]", kind: EventMessage, check: func(t *testing.T, e Event) {
+			if e.Text != "INFO: Homing done" {
+				t.Errorf("Text = %q", e.Text)
+			}
+		}},
+		{line: "[GC:G0 G54 G17 G21 G90 G94 M5 M9 T2 F500 S12000]", kind: EventGCode, check: func(t *testing.T, e Event) {
+			gc := e.GCode
+			if gc.Motion != "G0" || gc.WCS != "G54" || gc.Units != "G21" || gc.Spindle != "M5" {
+				t.Errorf("GCode = %+v", gc)
+			}
+			if gc.Tool != 2 || gc.Feed != 500 || gc.Speed != 12000 || len(gc.Coolant) != 1 {
+				t.Errorf("GCode = %+v", gc)
+			}
+		}},
+		{line: "[PRB:1.000,2.000,-3.500:1]", kind: EventProbe, check: func(t *testing.T, e Event) {
+			if !e.Probe.Success || e.Probe.Position.Z != -3.5 {
+				t.Errorf("Probe = %+v", e.Probe)
+			}
+		}},
+		{line: "[VER:3.7.8 FluidNC v3.7.8:]", kind: EventVersion},
+		{line: "[OPT:PHS,35,254]", kind: EventOptions},
+		{line: "Grbl 3.7 [FluidNC v3.7.8 (wifi) '$' for help]", kind: EventWelcome},
+		{line: "$100=80.000", kind: EventOther},
+		{line: "error:x", kind: EventError, wantErr: true},
+		{line: "ALARM:", kind: EventAlarm, wantErr: true},
+		{line: "[PRB:0,0,0:2]", kind: EventProbe, wantErr: true},
+		{line: "<Idle|MPos:a,0,0>", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			var p statusParser
+			event, err := p.parseLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if event.Kind != tt.kind {
+				t.Errorf("Kind = %s, want %s", event.Kind, tt.kind)
+			}
+			if tt.check != nil {
+				tt.check(t, event)
+			}
+		})
+	}
+}
+
+// TestCodeDescriptions tests the alarm and error tables
+func TestCodeDescriptions(t *testing.T) {
+	if got := AlarmDescription(1); got != "Hard limit" {
+		t.Errorf("AlarmDescription(1) = %q", got)
+	}
+	if got := AlarmDescription(99); got != "Unknown alarm" {
+		t.Errorf("AlarmDescription(99) = %q", got)
+	}
+	if got := ErrorDescription(20); got != "Unsupported command" {
+		t.Errorf("ErrorDescription(20) = %q", got)
+	}
+	if got := FormatError(999); got != "ERROR 999: Unknown error" {
+		t.Errorf("FormatError(999) = %q", got)
+	}
+}
@@ -0,0 +1,46 @@
+package fluidnc
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"time"
+)
+
+// serialTransport is a Transport over a tty device
+type serialTransport struct {
+	file   *os.File
+	reader *bufio.Reader
+}
+
+// ReadLine returns the next non-empty line
+func (t *serialTransport) ReadLine() (string, error) {
+	for {
+		t.file.SetReadDeadline(time.Now().Add(readWait))
+		line, err := t.reader.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+}
+
+// Write sends data as-is
+func (t *serialTransport) Write(data []byte) error {
+	t.file.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := t.file.Write(data)
+	return err
+}
+
+// Ping is a no-op: the status poll gets a reply within readWait or the
+// read deadline expires
+func (t *serialTransport) Ping() error {
+	return nil
+}
+
+// Close closes the device
+func (t *serialTransport) Close() error {
+	return t.file.Close()
+}
@@ -0,0 +1,96 @@
+package fluidnc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"golang.org/x/sys/unix"
+)
+
+// defaultBaudRate is the FluidNC and Grbl default
+const defaultBaudRate = 115200
+
+// baudRates maps supported baud rates to termios speed flags
+var baudRates = map[int]uint32{
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+	460800: unix.B460800,
+	500000: unix.B500000,
+	921600: unix.B921600,
+}
+
+// openSerial opens and configures the serial device
+func openSerial(config types.FluidNCConfig) (*serialTransport, error) {
+	baud := config.BaudRate
+	if baud == 0 {
+		baud = defaultBaudRate
+	}
+	speed, ok := baudRates[baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate %d", baud)
+	}
+
+	// Open without becoming the controlling terminal; the runtime poller
+	// then gives us read and write deadlines on the file
+	file, err := os.OpenFile(config.Device, os.O_RDWR|unix.O_NOCTTY|unix.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", config.Device, err)
+	}
+
+	if err := configureSerial(file, speed); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to configure %s: %w", config.Device, err)
+	}
+
+	return &serialTransport{
+		file:   file,
+		reader: bufio.NewReader(file),
+	}, nil
+}
+
+// configureSerial puts the tty in raw 8N1 mode at the given speed
+func configureSerial(file *os.File, speed uint32) error {
+	raw, err := file.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var ioctlErr error
+	err = raw.Control(func(fd uintptr) {
+		t, err := unix.IoctlGetTermios(int(fd), unix.TCGETS)
+		if err != nil {
+			ioctlErr = err
+			return
+		}
+
+		t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+			unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON | unix.IXOFF
+		t.Oflag &^= unix.OPOST
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		t.Cflag &^= unix.CSIZE | unix.PARENB | unix.CSTOPB | unix.CRTSCTS | unix.CBAUD
+		t.Cflag |= unix.CS8 | unix.CREAD | unix.CLOCAL | speed
+		t.Ispeed = speed
+		t.Ospeed = speed
+		t.Cc[unix.VMIN] = 1
+		t.Cc[unix.VTIME] = 0
+
+		if err := unix.IoctlSetTermios(int(fd), unix.TCSETS, t); err != nil {
+			ioctlErr = err
+			return
+		}
+
+		// Keep DTR/RTS low so opening the port does not reset the ESP32.
+		// Not every tty supports modem lines, so this is best effort.
+		unix.IoctlSetPointerInt(int(fd), unix.TIOCMBIC, unix.TIOCM_DTR|unix.TIOCM_RTS)
+	})
+	if err != nil {
+		return err
+	}
+	return ioctlErr
+}
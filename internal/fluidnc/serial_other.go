@@ -0,0 +1,14 @@
+//go:build !linux
+
+package fluidnc
+
+import (
+	"fmt"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// openSerial fails: configuring the tty is only implemented for Linux
+func openSerial(config types.FluidNCConfig) (*serialTransport, error) {
+	return nil, fmt.Errorf("serial transport is unsupported on this platform")
+}
@@ -0,0 +1,106 @@
+//go:build linux
+
+package fluidnc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a pseudo-terminal pair and returns the master and the
+// path of the slave device
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("pseudo-terminals not available: %v", err)
+	}
+
+	raw, err := master.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	var ioctlErr error
+	raw.Control(func(fd uintptr) {
+		if ioctlErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ioctlErr != nil {
+			return
+		}
+		n, ioctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPTN)
+	})
+	if ioctlErr != nil {
+		master.Close()
+		t.Skipf("failed to unlock pseudo-terminal: %v", ioctlErr)
+	}
+
+	return master, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+// TestClientSerial tests the client against a pseudo-terminal pair
+func TestClientSerial(t *testing.T) {
+	master, slave := openPTY(t)
+	defer master.Close()
+
+	polls := make(chan struct{}, 10)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := master.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, b := range buf[:n] {
+				if b == '?' {
+					polls <- struct{}{}
+					master.Write([]byte("<Idle|MPos:5.000,6.000,7.000|Bf:15,128|FS:0,0>\r\n"))
+				}
+			}
+		}
+	}()
+
+	client := NewClient(types.FluidNCConfig{
+		Device:         slave,
+		BaudRate:       115200,
+		StatusInterval: 0.05,
+	})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case <-polls:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status poll received")
+	}
+
+	select {
+	case status := <-client.Status():
+		if status.State != types.StateIdle || status.Coordinates.Z != 7 {
+			t.Errorf("status = %+v", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status received")
+	}
+}
+
+// TestOpenSerialErrors tests serial configuration errors
+func TestOpenSerialErrors(t *testing.T) {
+	if _, err := openSerial(types.FluidNCConfig{Device: "/dev/null", BaudRate: 12345}); err == nil {
+		t.Error("openSerial() with unsupported baud rate did not return error")
+	}
+	if _, err := openSerial(types.FluidNCConfig{Device: "/nonexistent/tty"}); err == nil {
+		t.Error("openSerial() with missing device did not return error")
+	}
+	if err := validateConfig(types.FluidNCConfig{Transport: TransportSerial}); err == nil {
+		t.Error("validateConfig() without device did not return error")
+	}
+}
@@ -0,0 +1,255 @@
+package fluidnc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// statusParser parses Grbl/FluidNC status reports.
+//
+// Some fields are only sent periodically (WCO, Ov) or only when non-empty
+// (A, Pn), so the parser keeps the last values seen and applies them to
+// reports that omit them.
+type statusParser struct {
+	wco         types.Coordinates
+	overrides   types.Overrides
+	accessories types.Accessories
+}
+
+// parseStatusMessage parses a status report such as
+// <Idle|MPos:0.000,0.000,0.000|Bf:15,128|FS:0,0|WCO:0.000,0.000,0.000>
+func (p *statusParser) parseStatusMessage(message string) (types.MachineStatus, error) {
+	status := types.MachineStatus{
+		SubState:    -1,
+		LastUpdated: time.Now(),
+	}
+
+	message = strings.TrimSpace(message)
+	if !strings.HasPrefix(message, "<") || !strings.HasSuffix(message, ">") {
+		return status, fmt.Errorf("invalid status report: %q", message)
+	}
+	message = message[1 : len(message)-1]
+
+	parts := strings.Split(message, "|")
+	if parts[0] == "" {
+		return status, fmt.Errorf("status report has no state")
+	}
+
+	state, sub, err := parseState(parts[0])
+	if err != nil {
+		return status, err
+	}
+	status.State = state
+	status.SubState = sub
+
+	var (
+		mpos, wpos         types.Coordinates
+		haveMPos, haveWPos bool
+		haveOv, haveA      bool
+	)
+
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return status, fmt.Errorf("malformed field %q", part)
+		}
+
+		switch key {
+		case "MPos":
+			if mpos, err = parseCoordinates(value); err != nil {
+				return status, fmt.Errorf("MPos: %w", err)
+			}
+			haveMPos = true
+		case "WPos":
+			if wpos, err = parseCoordinates(value); err != nil {
+				return status, fmt.Errorf("WPos: %w", err)
+			}
+			haveWPos = true
+		case "WCO":
+			wco, err := parseCoordinates(value)
+			if err != nil {
+				return status, fmt.Errorf("WCO: %w", err)
+			}
+			p.wco = wco
+		case "FS":
+			vals, err := parseFloats(value, 2)
+			if err != nil {
+				return status, fmt.Errorf("FS: %w", err)
+			}
+			status.FeedRate = vals[0]
+			status.SpindleSpeed = vals[1]
+		case "F":
+			if status.FeedRate, err = strconv.ParseFloat(value, 64); err != nil {
+				return status, fmt.Errorf("F: %w", err)
+			}
+		case "S":
+			if status.SpindleSpeed, err = strconv.ParseFloat(value, 64); err != nil {
+				return status, fmt.Errorf("S: %w", err)
+			}
+		case "Bf":
+			vals, err := parseInts(value, 2)
+			if err != nil {
+				return status, fmt.Errorf("Bf: %w", err)
+			}
+			status.BufferState = vals[0]
+			status.RXBuffer = vals[1]
+		case "Ln":
+			if status.LineNumber, err = strconv.Atoi(value); err != nil {
+				return status, fmt.Errorf("Ln: %w", err)
+			}
+		case "Ov":
+			vals, err := parseInts(value, 3)
+			if err != nil {
+				return status, fmt.Errorf("Ov: %w", err)
+			}
+			p.overrides = types.Overrides{Feed: vals[0], Rapid: vals[1], Spindle: vals[2]}
+			haveOv = true
+		case "A":
+			acc, err := parseAccessories(value)
+			if err != nil {
+				return status, fmt.Errorf("A: %w", err)
+			}
+			p.accessories = acc
+			haveA = true
+		case "Pn":
+			status.Pins = types.InputPins(value)
+		case "SD":
+			percent, file, _ := strings.Cut(value, ",")
+			v, err := strconv.ParseFloat(percent, 64)
+			if err != nil || v < 0 || v > 100 {
+				return status, fmt.Errorf("SD: invalid percentage %q", percent)
+			}
+			status.SD = types.SDStatus{Active: true, Percent: v, File: file}
+		default:
+			// Ignore fields we do not know about (e.g. firmware extensions)
+		}
+	}
+
+	// Grbl sends A: alongside Ov: only when an accessory is on, so an
+	// override report without it means everything is off.
+	if haveOv && !haveA {
+		p.accessories = types.Accessories{}
+	}
+	status.Overrides = p.overrides
+	status.Accessories = p.accessories
+	status.WorkOffset = p.wco
+
+	switch {
+	case haveMPos:
+		status.Coordinates = mpos
+		status.WorkCoordinates = mpos.Sub(p.wco)
+	case haveWPos:
+		status.WorkCoordinates = wpos
+		status.Coordinates = wpos.Add(p.wco)
+	default:
+		return status, fmt.Errorf("status report has no position")
+	}
+
+	return status, nil
+}
+
+// parseState parses the state field, e.g. "Idle" or "Hold:0"
+func parseState(field string) (types.MachineState, int, error) {
+	name, code, hasCode := strings.Cut(field, ":")
+
+	var state types.MachineState
+	switch types.MachineState(name) {
+	case types.StateIdle, types.StateRun, types.StateHold, types.StateJog,
+		types.StateAlarm, types.StateDoor, types.StateCheck, types.StateHome,
+		types.StateSleep:
+		state = types.MachineState(name)
+	default:
+		return types.StateUnknown, -1, fmt.Errorf("unknown machine state %q", name)
+	}
+
+	if !hasCode {
+		return state, -1, nil
+	}
+
+	sub, err := strconv.Atoi(code)
+	if err != nil || sub < 0 {
+		return state, -1, fmt.Errorf("invalid sub-state %q for %s", code, name)
+	}
+	return state, sub, nil
+}
+
+// parseCoordinates parses a comma separated list of 1 to 6 axis values
+func parseCoordinates(value string) (types.Coordinates, error) {
+	var coords types.Coordinates
+
+	fields := strings.Split(value, ",")
+	if len(fields) > types.MaxAxes {
+		return coords, fmt.Errorf("too many axes: %d", len(fields))
+	}
+
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return coords, fmt.Errorf("invalid %s value %q", types.AxisNames[i], f)
+		}
+		coords.SetAxis(i, v)
+	}
+	coords.Axes = len(fields)
+
+	return coords, nil
+}
+
+// parseFloats parses exactly n comma separated floats
+func parseFloats(value string, n int) ([]float64, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(fields))
+	}
+
+	vals := make([]float64, n)
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", f)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// parseInts parses exactly n comma separated integers
+func parseInts(value string, n int) ([]int, error) {
+	fields := strings.Split(value, ",")
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(fields))
+	}
+
+	vals := make([]int, n)
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q", f)
+		}
+		vals[i] = v
+	}
+	return vals, nil
+}
+
+// parseAccessories parses the accessory state letters, e.g. "SFM"
+func parseAccessories(value string) (types.Accessories, error) {
+	var acc types.Accessories
+	for _, r := range value {
+		switch r {
+		case 'S':
+			acc.SpindleCW = true
+		case 'C':
+			acc.SpindleCCW = true
+		case 'F':
+			acc.Flood = true
+		case 'M':
+			acc.Mist = true
+		default:
+			return acc, fmt.Errorf("unknown accessory %q", r)
+		}
+	}
+	return acc, nil
+}
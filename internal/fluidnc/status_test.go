@@ -0,0 +1,138 @@
+package fluidnc
+
+import (
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestParseStatusMessage tests parsing of individual status reports
+func TestParseStatusMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		check   func(t *testing.T, s types.MachineStatus)
+		wantErr bool
+	}{
+		{
+			name:    "idle with FS",
+			message: "<Idle|MPos:1.000,2.000,3.000|Bf:15,128|FS:500,12000>",
+			check: func(t *testing.T, s types.MachineStatus) {
+				if s.State != types.StateIdle || s.SubState != -1 {
+					t.Errorf("state = %s:%d, want Idle:-1", s.State, s.SubState)
+				}
+				if s.Coordinates.X != 1 || s.Coordinates.Y != 2 || s.Coordinates.Z != 3 || s.Coordinates.Axes != 3 {
+					t.Errorf("Coordinates = %+v", s.Coordinates)
+				}
+				if s.FeedRate != 500 || s.SpindleSpeed != 12000 {
+					t.Errorf("FS = %v,%v, want 500,12000", s.FeedRate, s.SpindleSpeed)
+				}
+				if s.BufferState != 15 || s.RXBuffer != 128 {
+					t.Errorf("Bf = %d,%d, want 15,128", s.BufferState, s.RXBuffer)
+				}
+			},
+		},
+		{
+			name:    "hold with sub-state",
+			message: "<Hold:0|MPos:0.000,0.000,0.000|F:100>",
+			check: func(t *testing.T, s types.MachineStatus) {
+				if s.State != types.StateHold || s.SubState != 0 {
+					t.Errorf("state = %s:%d, want Hold:0", s.State, s.SubState)
+				}
+				if s.FeedRate != 100 {
+					t.Errorf("FeedRate = %v, want 100", s.FeedRate)
+				}
+			},
+		},
+		{
+			name:    "work position with offset",
+			message: "<Run|WPos:1.000,1.000,1.000|WCO:10.000,20.000,-5.000|Ln:42>",
+			check: func(t *testing.T, s types.MachineStatus) {
+				if s.Coordinates.X != 11 || s.Coordinates.Y != 21 || s.Coordinates.Z != -4 {
+					t.Errorf("Coordinates = %+v, want 11,21,-4", s.Coordinates)
+				}
+				if s.WorkCoordinates.X != 1 || s.WorkOffset.Y != 20 {
+					t.Errorf("WorkCoordinates = %+v, WorkOffset = %+v", s.WorkCoordinates, s.WorkOffset)
+				}
+				if s.LineNumber != 42 {
+					t.Errorf("LineNumber = %d, want 42", s.LineNumber)
+				}
+			},
+		},
+		{
+			name:    "six axes",
+			message: "<Jog|MPos:1,2,3,4,5,6>",
+			check: func(t *testing.T, s types.MachineStatus) {
+				if s.Coordinates.Axes != 6 || s.Coordinates.A != 4 || s.Coordinates.C != 6 {
+					t.Errorf("Coordinates = %+v", s.Coordinates)
+				}
+			},
+		},
+		{
+			name:    "overrides, accessories and pins",
+			message: "<Door:1|MPos:0,0,0|Ov:120,50,90|A:SFM|Pn:XZD>",
+			check: func(t *testing.T, s types.MachineStatus) {
+				if s.State != types.StateDoor || s.SubState != 1 {
+					t.Errorf("state = %s:%d, want Door:1", s.State, s.SubState)
+				}
+				if s.Overrides != (types.Overrides{Feed: 120, Rapid: 50, Spindle: 90}) {
+					t.Errorf("Overrides = %+v", s.Overrides)
+				}
+				if s.Accessories != (types.Accessories{SpindleCW: true, Flood: true, Mist: true}) {
+					t.Errorf("Accessories = %+v", s.Accessories)
+				}
+				if !s.Pins.Has('X') || !s.Pins.Has('D') || s.Pins.Has('Y') {
+					t.Errorf("Pins = %q", s.Pins)
+				}
+			},
+		},
+		{name: "unknown state", message: "<Bogus|MPos:0,0,0>", wantErr: true},
+		{name: "bad coordinate", message: "<Idle|MPos:0,abc,0>", wantErr: true},
+		{name: "short FS", message: "<Idle|MPos:0,0,0|FS:100>", wantErr: true},
+		{name: "bad sub-state", message: "<Hold:x|MPos:0,0,0>", wantErr: true},
+		{name: "no position", message: "<Idle|FS:0,0>", wantErr: true},
+		{name: "not a status report", message: "ok", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p statusParser
+			status, err := p.parseStatusMessage(tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseStatusMessage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				tt.check(t, status)
+			}
+		})
+	}
+}
+
+// TestParseStatusMessageCache tests that periodic fields carry over between reports
+func TestParseStatusMessageCache(t *testing.T) {
+	var p statusParser
+
+	if _, err := p.parseStatusMessage("<Idle|MPos:10,10,10|WCO:5,5,5|Ov:100,100,100|A:S>"); err != nil {
+		t.Fatalf("parseStatusMessage() error = %v", err)
+	}
+
+	status, err := p.parseStatusMessage("<Run|MPos:15,10,10|FS:100,0>")
+	if err != nil {
+		t.Fatalf("parseStatusMessage() error = %v", err)
+	}
+	if status.WorkCoordinates.X != 10 || status.WorkCoordinates.Y != 5 {
+		t.Errorf("WorkCoordinates = %+v, want cached WCO applied", status.WorkCoordinates)
+	}
+	if !status.Accessories.SpindleCW || status.Overrides.Feed != 100 {
+		t.Errorf("Accessories = %+v, Overrides = %+v, want cached values", status.Accessories, status.Overrides)
+	}
+
+	// Ov without A means all accessories are off
+	status, err = p.parseStatusMessage("<Run|MPos:15,10,10|Ov:100,100,100>")
+	if err != nil {
+		t.Fatalf("parseStatusMessage() error = %v", err)
+	}
+	if status.Accessories.SpindleCW {
+		t.Errorf("Accessories = %+v, want all off", status.Accessories)
+	}
+}
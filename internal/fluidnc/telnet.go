@@ -0,0 +1,125 @@
+package fluidnc
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// Telnet protocol bytes
+const (
+	telnetIAC  = 255
+	telnetDONT = 254
+	telnetWILL = 251
+	telnetSB   = 250
+	telnetSE   = 240
+)
+
+// telnetTransport is a Transport over the FluidNC telnet server or a raw
+// TCP Grbl stream
+type telnetTransport struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// dialTelnet connects to the FluidNC telnet server
+func dialTelnet(ctx context.Context, config types.FluidNCConfig) (*telnetTransport, error) {
+	dialer := net.Dialer{
+		Timeout:   writeWait,
+		KeepAlive: pingPeriod,
+	}
+
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTelnetTransport(conn), nil
+}
+
+// newTelnetTransport wraps an established connection
+func newTelnetTransport(conn net.Conn) *telnetTransport {
+	return &telnetTransport{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+}
+
+// ReadLine returns the next non-empty line, dropping telnet negotiation
+func (t *telnetTransport) ReadLine() (string, error) {
+	var line []byte
+
+	for {
+		t.conn.SetReadDeadline(time.Now().Add(readWait))
+		b, err := t.reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch b {
+		case telnetIAC:
+			if err := t.skipCommand(); err != nil {
+				return "", err
+			}
+		case '\n':
+			if s := strings.TrimSpace(string(line)); s != "" {
+				return s, nil
+			}
+			line = line[:0]
+		default:
+			line = append(line, b)
+		}
+	}
+}
+
+// skipCommand discards a telnet command following an IAC byte
+func (t *telnetTransport) skipCommand() error {
+	cmd, err := t.reader.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cmd >= telnetWILL && cmd <= telnetDONT:
+		// Option negotiation carries one option byte
+		_, err = t.reader.ReadByte()
+	case cmd == telnetSB:
+		// Sub-negotiation runs until IAC SE
+		var prev byte
+		for {
+			b, err := t.reader.ReadByte()
+			if err != nil {
+				return err
+			}
+			if prev == telnetIAC && b == telnetSE {
+				return nil
+			}
+			prev = b
+		}
+	}
+	return err
+}
+
+// Write sends data as-is
+func (t *telnetTransport) Write(data []byte) error {
+	t.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := t.conn.Write(data)
+	return err
+}
+
+// Ping is a no-op: status polls keep the stream busy and TCP keep-alives
+// detect a dead peer
+func (t *telnetTransport) Ping() error {
+	return nil
+}
+
+// Close closes the TCP connection
+func (t *telnetTransport) Close() error {
+	return t.conn.Close()
+}
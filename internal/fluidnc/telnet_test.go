@@ -0,0 +1,108 @@
+package fluidnc
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+// TestTelnetReadLine tests line framing and telnet negotiation stripping
+func TestTelnetReadLine(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+
+	transport := newTelnetTransport(client)
+	defer transport.Close()
+
+	go func() {
+		server.Write([]byte{telnetIAC, telnetWILL, 1})
+		server.Write([]byte("Grbl 3.7 [FluidNC v3.7.8 (wifi) '$' for help]\r\n\r\n"))
+		server.Write([]byte{telnetIAC, telnetSB, 31, 0, 80, telnetIAC, telnetSE})
+		server.Write([]byte("<Idle|MPos:0.000,0.000,0.000|FS:0,0>\r\n"))
+	}()
+
+	want := []string{
+		"Grbl 3.7 [FluidNC v3.7.8 (wifi) '$' for help]",
+		"<Idle|MPos:0.000,0.000,0.000|FS:0,0>",
+	}
+	for _, w := range want {
+		line, err := transport.ReadLine()
+		if err != nil {
+			t.Fatalf("ReadLine() error = %v", err)
+		}
+		if line != w {
+			t.Errorf("ReadLine() = %q, want %q", line, w)
+		}
+	}
+}
+
+// TestClientTelnet tests the client end to end over a TCP stream
+func TestClientTelnet(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				return
+			}
+			if b == '?' {
+				conn.Write([]byte("<Run|WPos:1.000,2.000,3.000|FS:100,5000>\r\n"))
+			}
+		}
+	}()
+
+	client := NewClient(types.FluidNCConfig{
+		Host:           "127.0.0.1",
+		Port:           l.Addr().(*net.TCPAddr).Port,
+		Transport:      TransportTelnet,
+		StatusInterval: 0.05,
+	})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case status := <-client.Status():
+		if status.State != types.StateRun || status.WorkCoordinates.Y != 2 || status.SpindleSpeed != 5000 {
+			t.Errorf("status = %+v", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status received")
+	}
+}
+
+// TestTransportName tests transport selection from the configuration
+func TestTransportName(t *testing.T) {
+	tests := []struct {
+		config types.FluidNCConfig
+		want   string
+	}{
+		{types.FluidNCConfig{Port: 23}, TransportTelnet},
+		{types.FluidNCConfig{Port: 81}, TransportWebSocket},
+		{types.FluidNCConfig{Port: 2323, Transport: TransportTelnet}, TransportTelnet},
+		{types.FluidNCConfig{Port: 23, Transport: TransportWebSocket}, TransportWebSocket},
+	}
+
+	for _, tt := range tests {
+		if got := transportName(tt.config); got != tt.want {
+			t.Errorf("transportName(%+v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package fluidnc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+)
+
+const (
+	// TransportWebSocket talks to the FluidNC WebSocket server (port 81)
+	TransportWebSocket = "websocket"
+	// TransportTelnet talks to the FluidNC telnet server or any raw TCP
+	// Grbl stream (port 23)
+	TransportTelnet = "telnet"
+	// TransportSerial talks to a controller attached over USB serial
+	TransportSerial = "serial"
+)
+
+const (
+	// writeWait is the time allowed to write a message
+	writeWait = 10 * time.Second
+	// readWait is the time allowed without hearing from the controller
+	readWait = 60 * time.Second
+	// pingPeriod must be less than readWait
+	pingPeriod = (readWait * 9) / 10
+	// defaultStatusInterval is used when StatusInterval is not configured
+	defaultStatusInterval = 500 * time.Millisecond
+	// heartbeatInterval is the status poll rate while reports are pushed;
+	// FluidNC only pushes on change, so an idle machine would otherwise
+	// trip readWait
+	heartbeatInterval = readWait / 4
+)
+
+// Transport is a line-oriented connection to a FluidNC controller.
+//
+// ReadLine is called from a single reader goroutine and Write and Ping from
+// a single writer goroutine; Close may be called from anywhere.
+type Transport interface {
+	// ReadLine returns the next line received, without the line terminator
+	ReadLine() (string, error)
+	// Write sends raw bytes, either a real-time command or a terminated line
+	Write(data []byte) error
+	// Ping checks that the connection is still alive
+	Ping() error
+	// Close closes the connection
+	Close() error
+}
+
+// transportName returns the configured transport, defaulting to serial when
+// a device is set, telnet on port 23 and WebSocket everywhere else
+func transportName(config types.FluidNCConfig) string {
+	if config.Transport != "" {
+		return config.Transport
+	}
+	if config.Device != "" {
+		return TransportSerial
+	}
+	if config.Port == 23 {
+		return TransportTelnet
+	}
+	return TransportWebSocket
+}
+
+// dialTransport opens the transport selected by the configuration
+func dialTransport(ctx context.Context, config types.FluidNCConfig) (Transport, error) {
+	switch name := transportName(config); name {
+	case TransportWebSocket:
+		return dialWebSocket(ctx, config)
+	case TransportTelnet:
+		return dialTelnet(ctx, config)
+	case TransportSerial:
+		return openSerial(config)
+	default:
+		return nil, fmt.Errorf("unknown transport %q", name)
+	}
+}
+
+// validateConfig checks that the configuration names a reachable controller
+func validateConfig(config types.FluidNCConfig) error {
+	switch name := transportName(config); name {
+	case TransportWebSocket, TransportTelnet:
+		if config.Host == "" || config.Port <= 0 {
+			return fmt.Errorf("invalid FluidNC address %q:%d", config.Host, config.Port)
+		}
+	case TransportSerial:
+		if config.Device == "" {
+			return fmt.Errorf("no serial device configured")
+		}
+	default:
+		return fmt.Errorf("unknown transport %q", name)
+	}
+	return nil
+}
@@ -3,210 +3,105 @@ package fluidnc
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/url"
-	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
 	"github.com/gorilla/websocket"
 )
 
-// Client represents a FluidNC WebSocket client
-type Client struct {
-	config     types.FluidNCConfig
-	conn       *websocket.Conn
-	statusChan chan types.MachineStatus
-	done       chan struct{}
+// wsTransport is a Transport over the FluidNC WebSocket server
+type wsTransport struct {
+	conn    *websocket.Conn
+	pending []string
+	partial string // text after the last newline, completed by a later frame
 }
 
-// NewClient creates a new FluidNC WebSocket client
-func NewClient(config types.FluidNCConfig) *Client {
-	return &Client{
-		config:     config,
-		statusChan: make(chan types.MachineStatus, 10),
-		done:       make(chan struct{}),
-	}
-}
-
-// Connect connects to the FluidNC WebSocket server
-func (c *Client) Connect(ctx context.Context) error {
+// dialWebSocket connects to the FluidNC WebSocket server
+func dialWebSocket(ctx context.Context, config types.FluidNCConfig) (*wsTransport, error) {
 	// Create WebSocket URL
 	u := url.URL{
 		Scheme: "ws",
-		Host:   fmt.Sprintf("%s:%d", c.config.Host, c.config.Port),
+		Host:   fmt.Sprintf("%s:%d", config.Host, config.Port),
 		Path:   "/",
 	}
 
-	// Connect to WebSocket server
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
 	if err != nil {
-		return fmt.Errorf("failed to connect to FluidNC: %w", err)
-	}
-
-	c.conn = conn
-
-	// Start goroutines for reading and writing
-	go c.readPump(ctx)
-	go c.writePump(ctx)
-
-	return nil
-}
-
-// Disconnect disconnects from the FluidNC WebSocket server
-func (c *Client) Disconnect() error {
-	if c.conn != nil {
-		return c.conn.Close()
+		return nil, err
 	}
-	return nil
-}
 
-// Status returns a channel that receives machine status updates
-func (c *Client) Status() <-chan types.MachineStatus {
-	return c.statusChan
-}
-
-// Close closes the client
-func (c *Client) Close() {
-	close(c.done)
-	if c.conn != nil {
-		c.conn.Close()
-	}
-}
-
-// readPump pumps messages from the WebSocket connection to the status channel
-func (c *Client) readPump(ctx context.Context) {
-	defer func() {
-		c.conn.Close()
-	}()
-
-	c.conn.SetReadLimit(512)
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
-	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	conn.SetReadLimit(4096)
+	conn.SetReadDeadline(time.Now().Add(readWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(readWait))
 		return nil
 	})
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-c.done:
-			return
-		default:
-			_, message, err := c.conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("error: %v", err)
-				}
-				return
-			}
-
-			// Parse the message
-			status, err := parseStatusMessage(string(message))
-			if err != nil {
-				log.Printf("error parsing status message: %v", err)
-				continue
-			}
+	return &wsTransport{conn: conn}, nil
+}
 
-			// Send the status to the channel
-			select {
-			case c.statusChan <- status:
-			default:
-				// Channel is full, skip this update
-			}
+// ReadLine returns the next line, splitting frames that carry several lines
+// and joining lines that are split across frames
+func (t *wsTransport) ReadLine() (string, error) {
+	for len(t.pending) == 0 {
+		_, message, err := t.conn.ReadMessage()
+		if err != nil {
+			return "", err
 		}
-	}
-}
+		t.conn.SetReadDeadline(time.Now().Add(readWait))
 
-// writePump pumps messages from the status channel to the WebSocket connection
-func (c *Client) writePump(ctx context.Context) {
-	ticker := time.NewTicker(54 * time.Second)
-	defer func() {
-		ticker.Stop()
-		c.conn.Close()
-	}()
+		// Web UI control messages arrive as whole frames without a newline
+		if t.partial == "" && isWebUIControl(strings.TrimSpace(string(message))) {
+			continue
+		}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-c.done:
-			return
-		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
+		lines := strings.Split(t.partial+string(message), "\n")
+		t.partial = lines[len(lines)-1]
+		for _, line := range lines[:len(lines)-1] {
+			line = strings.TrimSpace(line)
+			if line != "" && !isWebUIControl(line) {
+				t.pending = append(t.pending, line)
 			}
-		default:
-			// Send status request
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := c.conn.WriteMessage(websocket.TextMessage, []byte("?")); err != nil {
-				return
-			}
-
-			// Wait for the status interval
-			time.Sleep(time.Duration(c.config.StatusInterval*1000) * time.Millisecond)
 		}
 	}
+
+	line := t.pending[0]
+	t.pending = t.pending[1:]
+	return line, nil
 }
 
-// parseStatusMessage parses a status message from FluidNC
-func parseStatusMessage(message string) (types.MachineStatus, error) {
-	// Example message: <Idle|MPos:0.000,0.000,0.000|Bf:15,100|F:0|FS:0,0>
-	status := types.MachineStatus{
-		LastUpdated: time.Now(),
+// Write sends data as a text frame, or as a binary frame if it is not
+// valid UTF-8 as real-time bytes of 0x80 and above are not
+func (t *wsTransport) Write(data []byte) error {
+	messageType := websocket.TextMessage
+	if !utf8.Valid(data) {
+		messageType = websocket.BinaryMessage
 	}
+	t.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return t.conn.WriteMessage(messageType, data)
+}
 
-	// Remove < and >
-	message = strings.TrimPrefix(message, "<")
-	message = strings.TrimSuffix(message, ">")
-
-	// Split by |
-	parts := strings.Split(message, "|")
-	if len(parts) < 1 {
-		return status, fmt.Errorf("invalid message format")
-	}
+// Ping sends a WebSocket ping; a missing pong lets the read deadline expire
+func (t *wsTransport) Ping() error {
+	t.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return t.conn.WriteMessage(websocket.PingMessage, nil)
+}
 
-	// Parse state
-	status.State = types.MachineState(parts[0])
+// Close closes the WebSocket connection
+func (t *wsTransport) Close() error {
+	return t.conn.Close()
+}
 
-	// Parse coordinates
-	for i := 1; i < len(parts); i++ {
-		part := parts[i]
-		if strings.HasPrefix(part, "MPos:") {
-			coords := strings.Split(strings.TrimPrefix(part, "MPos:"), ",")
-			if len(coords) >= 3 {
-				status.Coordinates.X = parseFloat(coords[0])
-				status.Coordinates.Y = parseFloat(coords[1])
-				status.Coordinates.Z = parseFloat(coords[2])
-			}
-		} else if strings.HasPrefix(part, "F:") {
-			status.FeedRate = parseFloat(strings.TrimPrefix(part, "F:"))
-		} else if strings.HasPrefix(part, "S:") {
-			status.SpindleSpeed = parseFloat(strings.TrimPrefix(part, "S:"))
-		} else if strings.HasPrefix(part, "Bf:") {
-			buf := strings.Split(strings.TrimPrefix(part, "Bf:"), ",")
-			if len(buf) >= 2 {
-				status.BufferState = parseInt(buf[0])
-			}
-		} else if strings.HasPrefix(part, "Ln:") {
-			status.LineNumber = parseInt(strings.TrimPrefix(part, "Ln:"))
+// isWebUIControl reports whether a line is one of the control messages the
+// FluidNC WebSocket server sends for its web UI rather than Grbl output
+func isWebUIControl(line string) bool {
+	for _, prefix := range []string{"CURRENT_ID:", "ACTIVE_ID:", "PING:"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
 		}
 	}
-
-	return status, nil
-}
-
-// parseFloat parses a float from a string
-func parseFloat(s string) float64 {
-	f, _ := strconv.ParseFloat(s, 64)
-	return f
+	return false
 }
-
-// parseInt parses an int from a string
-func parseInt(s string) int {
-	i, _ := strconv.Atoi(s)
-	return i
-} 
\ No newline at end of file
@@ -0,0 +1,115 @@
+package fluidnc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/gorilla/websocket"
+)
+
+// TestWebSocketReadLine tests that lines split across frames are joined and
+// web UI control frames are skipped
+func TestWebSocketReadLine(t *testing.T) {
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for _, frame := range []string{
+			"CURRENT_ID:0",
+			"ok\n<Run|WPos:1.000,2.0",
+			"00,3.000|FS:100,5000>\r\n[MSG:",
+			"INFO: Hello]\n",
+		} {
+			conn.WriteMessage(websocket.TextMessage, []byte(frame))
+		}
+		conn.ReadMessage()
+	}))
+	defer srv.Close()
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	transport, err := dialWebSocket(context.Background(), types.FluidNCConfig{Host: host, Port: p})
+	if err != nil {
+		t.Fatalf("dialWebSocket() error = %v", err)
+	}
+	defer transport.Close()
+
+	want := []string{
+		"ok",
+		"<Run|WPos:1.000,2.000,3.000|FS:100,5000>",
+		"[MSG:INFO: Hello]",
+	}
+	for _, w := range want {
+		line, err := transport.ReadLine()
+		if err != nil {
+			t.Fatalf("ReadLine() error = %v", err)
+		}
+		if line != w {
+			t.Errorf("ReadLine() = %q, want %q", line, w)
+		}
+	}
+}
+
+// TestWebSocketRealtimeBinary tests that real-time bytes that are not valid
+// UTF-8 go out as binary frames and line commands as text frames
+func TestWebSocketRealtimeBinary(t *testing.T) {
+	type frame struct {
+		kind int
+		data string
+	}
+	frames := make(chan frame, 4)
+
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			kind, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			frames <- frame{kind, string(data)}
+		}
+	}))
+	defer srv.Close()
+
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	transport, err := dialWebSocket(context.Background(), types.FluidNCConfig{Host: host, Port: p})
+	if err != nil {
+		t.Fatalf("dialWebSocket() error = %v", err)
+	}
+	defer transport.Close()
+
+	want := []frame{
+		{websocket.BinaryMessage, string([]byte{RealtimeFeedPlus10})},
+		{websocket.TextMessage, "$X\n"},
+	}
+	for _, w := range want {
+		if err := transport.Write([]byte(w.data)); err != nil {
+			t.Fatalf("Write(%q) error = %v", w.data, err)
+		}
+		select {
+		case got := <-frames:
+			if got != w {
+				t.Errorf("frame = %+v, want %+v", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("frame %q not received", w.data)
+		}
+	}
+}
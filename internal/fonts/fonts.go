@@ -0,0 +1,43 @@
+// Package fonts loads the font files named in the configuration
+package fonts
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fkcurrie/fluidnc-led-golang/internal/types"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font/ttf"
+)
+
+// Register loads every configured font and registers it under its name so
+// layouts can use it
+func Register(configs []types.FontConfig) error {
+	for _, cfg := range configs {
+		if cfg.Name == "" {
+			return fmt.Errorf("font %s: name is required", cfg.Path)
+		}
+		face, err := load(cfg)
+		if err != nil {
+			return fmt.Errorf("font %q: %w", cfg.Name, err)
+		}
+		font.Register(cfg.Name, face)
+	}
+	return nil
+}
+
+// load reads a font file according to its extension
+func load(cfg types.FontConfig) (*font.Face, error) {
+	switch strings.ToLower(filepath.Ext(cfg.Path)) {
+	case ".bdf":
+		return font.LoadBDF(cfg.Path)
+	case ".ttf", ".otf":
+		return ttf.Load(cfg.Path, ttf.Options{
+			Size:    cfg.Size,
+			Hinting: cfg.Hinting,
+			Levels:  cfg.Levels,
+		})
+	}
+	return nil, fmt.Errorf("unsupported font file %s", cfg.Path)
+}
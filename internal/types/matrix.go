@@ -1,6 +1,10 @@
 package types
 
-import "image/color"
+import (
+	"image/color"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
+)
 
 // Matrix represents a display matrix
 type Matrix interface {
@@ -10,6 +14,8 @@ type Matrix interface {
 	SetPixel(x, y int, c color.Color) error
 	// Show updates the display with the current buffer
 	Show() error
+	// Present shows a whole frame; it may update only fb.Dirty()
+	Present(fb *framebuffer.FrameBuffer) error
 	// Close closes the matrix
 	Close() error
-} 
\ No newline at end of file
+}
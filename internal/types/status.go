@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -21,30 +23,218 @@ const (
 	StateUnknown   MachineState = "Unknown"
 )
 
-// Coordinates represents the X, Y, Z coordinates of the machine
+// MaxAxes is the maximum number of axes reported by FluidNC
+const MaxAxes = 6
+
+// AxisNames lists the axis letters in the order they appear in status reports
+var AxisNames = [MaxAxes]string{"X", "Y", "Z", "A", "B", "C"}
+
+// Coordinates represents the position of the machine on up to six axes
 type Coordinates struct {
 	X float64
 	Y float64
 	Z float64
+	A float64
+	B float64
+	C float64
+	// Axes is the number of axes reported by the controller
+	Axes int
+}
+
+// Axis returns the value of the i-th axis in status report order
+func (c Coordinates) Axis(i int) float64 {
+	switch i {
+	case 0:
+		return c.X
+	case 1:
+		return c.Y
+	case 2:
+		return c.Z
+	case 3:
+		return c.A
+	case 4:
+		return c.B
+	case 5:
+		return c.C
+	}
+	return 0
+}
+
+// SetAxis sets the value of the i-th axis in status report order
+func (c *Coordinates) SetAxis(i int, v float64) {
+	switch i {
+	case 0:
+		c.X = v
+	case 1:
+		c.Y = v
+	case 2:
+		c.Z = v
+	case 3:
+		c.A = v
+	case 4:
+		c.B = v
+	case 5:
+		c.C = v
+	}
+}
+
+// Add returns the axis-wise sum of c and o
+func (c Coordinates) Add(o Coordinates) Coordinates {
+	r := Coordinates{Axes: c.Axes}
+	for i := 0; i < MaxAxes; i++ {
+		r.SetAxis(i, c.Axis(i)+o.Axis(i))
+	}
+	return r
+}
+
+// Sub returns the axis-wise difference of c and o
+func (c Coordinates) Sub(o Coordinates) Coordinates {
+	r := Coordinates{Axes: c.Axes}
+	for i := 0; i < MaxAxes; i++ {
+		r.SetAxis(i, c.Axis(i)-o.Axis(i))
+	}
+	return r
+}
+
+// Overrides represents the feed, rapid and spindle override percentages
+type Overrides struct {
+	Feed    int
+	Rapid   int
+	Spindle int
+}
+
+// Accessories represents the state of the spindle and coolant outputs
+type Accessories struct {
+	SpindleCW  bool
+	SpindleCCW bool
+	Flood      bool
+	Mist       bool
+}
+
+// InputPins holds the letters of the active input pins, e.g. "XYP"
+type InputPins string
+
+// Has reports whether the given pin letter is active
+func (p InputPins) Has(pin rune) bool {
+	return strings.ContainsRune(string(p), pin)
+}
+
+// SDStatus represents the progress of a file running from the SD card
+type SDStatus struct {
+	// Active is set while the status report carries an SD field
+	Active  bool
+	Percent float64
+	File    string
 }
 
 // MachineStatus represents the complete status of the FluidNC machine
 type MachineStatus struct {
-	State       MachineState
+	State MachineState
+	// SubState is the state sub-code (e.g. Hold:0, Door:1), or -1 if none
+	SubState int
+	// Coordinates is the machine position (MPos)
 	Coordinates Coordinates
-	FeedRate    float64
+	// WorkCoordinates is the work position (WPos)
+	WorkCoordinates Coordinates
+	// WorkOffset is the work coordinate offset (WCO)
+	WorkOffset   Coordinates
+	FeedRate     float64
 	SpindleSpeed float64
-	BufferState  int
-	LineNumber   int
-	LastUpdated  time.Time
+	Overrides    Overrides
+	Accessories  Accessories
+	Pins         InputPins
+	// BufferState is the number of available planner blocks
+	BufferState int
+	// RXBuffer is the number of available bytes in the serial RX buffer
+	RXBuffer    int
+	LineNumber  int
+	SD          SDStatus
+	LastUpdated time.Time
+}
+
+// JobStatus represents the progress of the current or last job
+type JobStatus struct {
+	// Active is set from the start of a job until the machine is idle again
+	Active  bool
+	File    string
+	Percent float64
+	Started time.Time
+	Elapsed time.Duration
+	// Remaining is the estimated time left, 0 if not yet known
+	Remaining time.Duration
 }
 
+// FirmwareFluidNC and FirmwareGrbl identify the controller firmware
+const (
+	FirmwareFluidNC = "FluidNC"
+	FirmwareGrbl    = "Grbl"
+)
+
+// ControllerInfo describes the controller firmware and its capabilities
+type ControllerInfo struct {
+	// Firmware is FirmwareFluidNC, FirmwareGrbl or empty if not yet known
+	Firmware string
+	Version  string
+	Hostname string
+	// Axes is the number of axes, 0 if not reported
+	Axes int
+	// Options holds the build option letters from [OPT:...]
+	Options string
+	// PlannerBlocks and RXBufferSize are the buffer sizes from [OPT:...]
+	PlannerBlocks int
+	RXBufferSize  int
+}
+
+// IsFluidNC reports whether the controller runs FluidNC
+func (i ControllerInfo) IsFluidNC() bool {
+	return i.Firmware == FirmwareFluidNC
+}
+
+// Summary returns short lines describing the controller for a splash page
+func (i ControllerInfo) Summary() []string {
+	var lines []string
+	if i.Firmware != "" {
+		lines = append(lines, i.Firmware)
+	}
+	if i.Version != "" {
+		lines = append(lines, "v"+strings.TrimPrefix(i.Version, "v"))
+	}
+	if i.Hostname != "" {
+		lines = append(lines, i.Hostname)
+	}
+	if i.Axes > 0 {
+		lines = append(lines, fmt.Sprintf("%d axes", i.Axes))
+	}
+	return lines
+}
+
+// ConnectionState represents the state of the connection to FluidNC
+type ConnectionState string
+
+const (
+	// Possible connection states
+	ConnectionDisconnected ConnectionState = "Disconnected"
+	ConnectionConnecting   ConnectionState = "Connecting"
+	ConnectionConnected    ConnectionState = "Connected"
+	ConnectionLost         ConnectionState = "Lost"
+	ConnectionGivingUp     ConnectionState = "GivingUp"
+)
+
 // DisplayData represents the data to be displayed on the LED matrix
 type DisplayData struct {
+	// Name identifies the machine when several are monitored
+	Name          string
 	MachineStatus MachineStatus
 	IPAddress     string
 	Connected     bool
-	LastUpdated   time.Time
+	Connection    ConnectionState
+	Controller    ControllerInfo
+	// AlarmCode is the last ALARM:n reported by the controller, 0 if none
+	AlarmCode int
+	// Message is the last [MSG:...] reported by the controller
+	Message     string
+	Job         JobStatus
+	LastUpdated time.Time
 }
 
 // MatrixConfig represents the configuration for the LED matrix
@@ -59,22 +249,111 @@ type MatrixConfig struct {
 
 // DisplayConfig represents the configuration for the display
 type DisplayConfig struct {
-	Width           int
-	Height          int
-	Brightness      int
-	UpdateInterval  float64
+	Width          int     `json:"width"`
+	Height         int     `json:"height"`
+	Brightness     int     `json:"brightness"`
+	UpdateInterval float64 `json:"update_interval"`
+	// MachineView is "rotate" or "summary" when several machines are monitored
+	MachineView string `json:"machine_view"`
+	// RotateInterval is the time each machine is shown in rotate view, in seconds
+	RotateInterval float64 `json:"rotate_interval"`
+	// Backend selects the matrix: "hardware", "png" or "terminal". It
+	// defaults to "hardware" in builds with the rpi5 tag and "png" otherwise
+	Backend string `json:"backend"`
+	// GPIOPin is the data pin of the hardware matrix
+	GPIOPin   int             `json:"gpio_pin"`
+	Simulator SimulatorConfig `json:"simulator"`
+	// Layout is a JSON or YAML file of screen definitions replacing the
+	// built-in layout
+	Layout string `json:"layout"`
+	// Fonts are loaded at startup and can be named by layout widgets
+	Fonts []FontConfig `json:"fonts"`
+	// Splash is an image shown at startup until the controller is known
+	Splash ImageConfig `json:"splash"`
+	// Icons are loaded at startup and replace or add to the built-in icons
+	Icons []ImageConfig `json:"icons"`
+}
+
+// ImageConfig represents an image file rendered for the display
+type ImageConfig struct {
+	// Name is how layouts refer to an icon
+	Name string `json:"name"`
+	// Path is an SVG, PNG, GIF or JPEG file
+	Path string `json:"path"`
+	// Width and Height are the rendered size; a splash defaults to the
+	// panel and an icon to 7x7
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Mode is "fit" (the default), "fill" or "stretch"
+	Mode string `json:"mode"`
+	// Tint is a "#rrggbb" color multiplied with the image's colors
+	Tint string `json:"tint"`
+	// Color draws an icon with its own colors instead of in the color of
+	// the widget
+	Color bool `json:"color"`
+}
+
+// FontConfig represents a font file loaded for use in layouts
+type FontConfig struct {
+	// Name is how layouts refer to the font
+	Name string `json:"name"`
+	// Path is a BDF, TrueType or OpenType file; OpenType fonts must have
+	// TrueType outlines, not the CFF outlines of most .otf files
+	Path string `json:"path"`
+	// Size is the em size in pixels of an outline font
+	Size float64 `json:"size"`
+	// Hinting is "full" (the default) or "none"
+	Hinting string `json:"hinting"`
+	// Levels is the number of brightness levels antialiasing is reduced
+	// to, 2 for none and 0 for full 8-bit coverage
+	Levels int `json:"levels"`
+}
+
+// SimulatorConfig represents the configuration of the simulated matrices
+type SimulatorConfig struct {
+	// Dir is the directory PNG frames are written to
+	Dir string `json:"dir"`
+	// Pitch is the size of one LED in output pixels, 1 if not set
+	Pitch int `json:"pitch"`
+	// Dots draws each LED as a round dot with a gap around it
+	Dots bool `json:"dots"`
+	// Sequence writes numbered frames instead of replacing frame.png
+	Sequence bool `json:"sequence"`
 }
 
 // FluidNCConfig represents the configuration for the FluidNC connection
 type FluidNCConfig struct {
-	Host              string
-	Port              int
-	ReconnectInterval int
-	StatusInterval    float64
+	// Name identifies the machine on the display, defaults to Host or Device
+	Name string `json:"name"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	// Transport is "websocket", "telnet" or "serial"; empty selects serial
+	// when Device is set, telnet on port 23 and WebSocket otherwise
+	Transport string `json:"transport"`
+	// Device is the serial device, e.g. /dev/ttyUSB0
+	Device string `json:"device"`
+	// BaudRate is the serial speed, 115200 if not set
+	BaudRate int `json:"baud_rate"`
+	// ReconnectInterval is the maximum delay between reconnect attempts in seconds
+	ReconnectInterval int `json:"reconnect_interval"`
+	// MaxReconnectAttempts is the number of consecutive failed attempts
+	// before giving up, 0 retries forever
+	MaxReconnectAttempts int     `json:"max_reconnect_attempts"`
+	StatusInterval       float64 `json:"status_interval"`
 }
 
 // DiscoveryConfig represents the configuration for the FluidNC discovery
 type DiscoveryConfig struct {
-	ScanInterval int
-	Timeout      int
-} 
\ No newline at end of file
+	// ScanInterval is the time between scans in seconds
+	ScanInterval int `json:"scan_interval"`
+	// Timeout is the time allowed to probe one host in seconds
+	Timeout int `json:"timeout"`
+	// Networks are CIDR ranges to scan, the local interface networks if empty
+	Networks []string `json:"networks"`
+	// Ports are the ports probed on every host, 81 if empty
+	Ports []int `json:"ports"`
+	// Workers is the number of hosts probed at once, 64 if not set
+	Workers int `json:"workers"`
+	// Registry is the JSON file known devices are persisted to
+	Registry string `json:"registry"`
+}
@@ -0,0 +1,250 @@
+// Package asset loads image files and renders them at the resolution of an
+// LED panel. PNG, GIF and JPEG are supported; vector formats are added by
+// importing a package that registers them, e.g. pkg/asset/svg.
+package asset
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Modes of placing an image in a size that has a different aspect ratio
+const (
+	// ModeFit scales the whole image into the size, centered, leaving the
+	// rest transparent
+	ModeFit = "fit"
+	// ModeFill scales the image to cover the size, centered, cropping what
+	// falls outside
+	ModeFill = "fill"
+	// ModeStretch scales the image to the size, ignoring its aspect ratio
+	ModeStretch = "stretch"
+)
+
+// Image is a decoded image that can be rendered at any size
+type Image interface {
+	// Size returns the natural width and height of the image
+	Size() (w, h float64)
+	// Draw renders the image scaled to r of dst; r may extend beyond dst
+	Draw(dst *image.RGBA, r image.Rectangle)
+}
+
+// Decoder decodes a vector image
+type Decoder func(r io.Reader) (Image, error)
+
+// Options control how an image is rendered
+type Options struct {
+	// Size is the size of the result, the image's natural size if zero
+	Size image.Point
+	// Mode is fit (the default), fill or stretch
+	Mode string
+	// Tint multiplies the colors of the image, white keeping them
+	Tint color.Color
+}
+
+// maxNaturalSize limits images rendered at their natural size
+const maxNaturalSize = 4096
+
+var (
+	mu       sync.RWMutex
+	decoders = map[string]Decoder{}
+)
+
+// Register makes a vector format available to Open for files with the
+// given extension, e.g. ".svg"
+func Register(ext string, decode Decoder) {
+	mu.Lock()
+	defer mu.Unlock()
+	decoders[strings.ToLower(ext)] = decode
+}
+
+// Load opens the image file at path and renders it
+func Load(path string, opts Options) (*image.RGBA, error) {
+	img, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	out, err := Render(img, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return out, nil
+}
+
+// Open decodes the image file at path, by its extension if a vector format
+// is registered for it and by its content otherwise
+func Open(path string) (Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	mu.RLock()
+	decode, ok := decoders[strings.ToLower(filepath.Ext(path))]
+	mu.RUnlock()
+	if !ok {
+		decode = decodeRaster
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+// decodeRaster decodes a format known to the image package; animated GIFs
+// decode to their first frame
+func decodeRaster(r io.Reader) (Image, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return FromImage(src), nil
+}
+
+// Render draws img into a new image according to opts
+func Render(img Image, opts Options) (*image.RGBA, error) {
+	w, h := img.Size()
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("image has no size")
+	}
+	size := opts.Size
+	if size.X <= 0 || size.Y <= 0 {
+		if w > maxNaturalSize || h > maxNaturalSize {
+			return nil, fmt.Errorf("%vx%v is too large to render without a size", w, h)
+		}
+		size = image.Pt(int(math.Ceil(w)), int(math.Ceil(h)))
+	}
+	r, err := Place(w, h, size, opts.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA(image.Rectangle{Max: size})
+	img.Draw(dst, r)
+	if opts.Tint != nil {
+		Tint(dst, opts.Tint)
+	}
+	return dst, nil
+}
+
+// Place returns where an image of w by h is drawn in size. The rectangle is
+// centered and, in fill mode, extends beyond size.
+func Place(w, h float64, size image.Point, mode string) (image.Rectangle, error) {
+	full := image.Rectangle{Max: size}
+	var scale float64
+	switch mode {
+	case "", ModeFit:
+		scale = math.Min(float64(size.X)/w, float64(size.Y)/h)
+	case ModeFill:
+		scale = math.Max(float64(size.X)/w, float64(size.Y)/h)
+	case ModeStretch:
+		return full, nil
+	default:
+		return image.Rectangle{}, fmt.Errorf("unknown mode %q", mode)
+	}
+
+	dw := int(math.Round(w * scale))
+	dh := int(math.Round(h * scale))
+	min := image.Pt((size.X-dw)/2, (size.Y-dh)/2)
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(dw, dh))}, nil
+}
+
+// Tint multiplies every pixel of img by c, so white takes the color of c
+// and black stays black
+func Tint(img *image.RGBA, c color.Color) {
+	tr, tg, tb, _ := c.RGBA()
+	for i := 0; i+3 < len(img.Pix); i += 4 {
+		img.Pix[i] = uint8(uint32(img.Pix[i]) * tr / 0xffff)
+		img.Pix[i+1] = uint8(uint32(img.Pix[i+1]) * tg / 0xffff)
+		img.Pix[i+2] = uint8(uint32(img.Pix[i+2]) * tb / 0xffff)
+	}
+}
+
+// Mask returns the alpha channel of img, for drawing it in a single color
+func Mask(img image.Image) *image.Alpha {
+	b := img.Bounds()
+	mask := image.NewAlpha(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			mask.SetAlpha(x, y, color.Alpha{A: uint8(a >> 8)})
+		}
+	}
+	return mask
+}
+
+// raster is a bitmap image scaled by averaging the pixels each output pixel
+// covers
+type raster struct {
+	src image.Image
+}
+
+// FromImage wraps a bitmap so it can be rendered at any size
+func FromImage(src image.Image) Image {
+	return raster{src: src}
+}
+
+// Size returns the size of the bitmap
+func (r raster) Size() (w, h float64) {
+	b := r.src.Bounds()
+	return float64(b.Dx()), float64(b.Dy())
+}
+
+// Draw scales the bitmap to dr of dst
+func (r raster) Draw(dst *image.RGBA, dr image.Rectangle) {
+	sb := r.src.Bounds()
+	if dr.Empty() || sb.Empty() {
+		return
+	}
+	sx := float64(sb.Dx()) / float64(dr.Dx())
+	sy := float64(sb.Dy()) / float64(dr.Dy())
+
+	clip := dr.Intersect(dst.Bounds())
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		y0 := float64(y-dr.Min.Y) * sy
+		for x := clip.Min.X; x < clip.Max.X; x++ {
+			x0 := float64(x-dr.Min.X) * sx
+			dst.SetRGBA(x, y, r.average(sb, x0, y0, x0+sx, y0+sy))
+		}
+	}
+}
+
+// average returns the mean premultiplied color of the source area from
+// (x0, y0) to (x1, y1), weighting pixels by how much of them it covers
+func (r raster) average(sb image.Rectangle, x0, y0, x1, y1 float64) color.RGBA {
+	var sr, sg, sbl, sa, total float64
+	for py := int(y0); float64(py) < y1; py++ {
+		wy := math.Min(float64(py+1), y1) - math.Max(float64(py), y0)
+		for px := int(x0); float64(px) < x1; px++ {
+			wx := math.Min(float64(px+1), x1) - math.Max(float64(px), x0)
+			weight := wx * wy
+			cr, cg, cb, ca := r.src.At(sb.Min.X+px, sb.Min.Y+py).RGBA()
+			sr += float64(cr) * weight
+			sg += float64(cg) * weight
+			sbl += float64(cb) * weight
+			sa += float64(ca) * weight
+			total += weight
+		}
+	}
+	if total == 0 {
+		return color.RGBA{}
+	}
+	return color.RGBA{
+		R: uint8(sr / total / 0x101),
+		G: uint8(sg / total / 0x101),
+		B: uint8(sbl / total / 0x101),
+		A: uint8(sa / total / 0x101),
+	}
+}
@@ -0,0 +1,108 @@
+package asset
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPlace tests placing images with other aspect ratios
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name string
+		w, h float64
+		size image.Point
+		mode string
+		want image.Rectangle
+	}{
+		{"fit wide", 32, 32, image.Pt(64, 32), ModeFit, image.Rect(16, 0, 48, 32)},
+		{"fit tall", 64, 16, image.Pt(32, 32), "", image.Rect(0, 12, 32, 20)},
+		{"fill wide", 32, 32, image.Pt(64, 32), ModeFill, image.Rect(0, -16, 64, 48)},
+		{"stretch", 10, 20, image.Pt(64, 32), ModeStretch, image.Rect(0, 0, 64, 32)},
+		{"upscale", 7, 7, image.Pt(14, 14), ModeFit, image.Rect(0, 0, 14, 14)},
+	}
+	for _, tt := range tests {
+		got, err := Place(tt.w, tt.h, tt.size, tt.mode)
+		if err != nil {
+			t.Fatalf("%s: Place() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: Place() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := Place(1, 1, image.Pt(1, 1), "zoom"); err == nil {
+		t.Errorf("Place(zoom) error = nil")
+	}
+}
+
+// TestRender tests that downscaling averages the pixels covered
+func TestRender(t *testing.T) {
+	// Left half red, right half transparent
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			src.SetRGBA(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+		want []color.RGBA
+	}{
+		{"natural size", Options{}, []color.RGBA{{R: 255, A: 255}, {R: 255, A: 255}, {}, {}}},
+		{"half", Options{Size: image.Pt(2, 1)}, []color.RGBA{{R: 255, A: 255}, {}}},
+		{"quarter", Options{Size: image.Pt(1, 1), Mode: ModeStretch}, []color.RGBA{{R: 127, A: 127}}},
+		{"tinted", Options{Size: image.Pt(2, 1), Tint: color.RGBA{R: 128, G: 255, B: 255, A: 255}}, []color.RGBA{{R: 128, A: 255}, {}}},
+	}
+	for _, tt := range tests {
+		img, err := Render(FromImage(src), tt.opts)
+		if err != nil {
+			t.Fatalf("%s: Render() error = %v", tt.name, err)
+		}
+		for i, want := range tt.want {
+			if got := img.RGBAAt(i, 0); got != want {
+				t.Errorf("%s: pixel %d = %v, want %v", tt.name, i, got, want)
+			}
+		}
+	}
+}
+
+// TestLoad tests decoding a PNG file and registered vector formats
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	src := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	src.SetNRGBA(0, 0, color.NRGBA{G: 255, A: 255})
+	path := filepath.Join(dir, "icon.png")
+	file, _ := os.Create(path)
+	png.Encode(file, src)
+	file.Close()
+
+	img, err := Load(path, Options{Size: image.Pt(4, 4)})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := img.RGBAAt(0, 0); got != (color.RGBA{G: 63, A: 63}) {
+		t.Errorf("Load() pixel = %v, want a quarter green", got)
+	}
+	if got := Mask(img).AlphaAt(0, 0).A; got != 63 {
+		t.Errorf("Mask() alpha = %d, want 63", got)
+	}
+
+	Register(".box", func(r io.Reader) (Image, error) {
+		return FromImage(image.NewRGBA(image.Rectangle{})), nil
+	})
+	os.WriteFile(filepath.Join(dir, "empty.box"), nil, 0o644)
+	if _, err := Load(filepath.Join(dir, "empty.box"), Options{}); err == nil {
+		t.Errorf("Load() of an empty image error = nil")
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.png"), Options{}); err == nil {
+		t.Errorf("Load() of a missing file error = nil")
+	}
+}
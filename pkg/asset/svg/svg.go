@@ -0,0 +1,45 @@
+// Package svg renders SVG images with oksvg. Importing it registers the
+// format with pkg/asset for files ending in .svg.
+package svg
+
+import (
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/asset"
+	"github.com/srwiley/oksvg"
+	"github.com/srwiley/rasterx"
+)
+
+func init() {
+	asset.Register(".svg", Decode)
+}
+
+// icon is a parsed SVG document
+type icon struct {
+	svg *oksvg.SvgIcon
+}
+
+// Decode parses an SVG document. Unsupported elements are skipped with a
+// warning rather than failing the whole image.
+func Decode(r io.Reader) (asset.Image, error) {
+	svg, err := oksvg.ReadIconStream(r, oksvg.WarnErrorMode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SVG: %w", err)
+	}
+	return icon{svg: svg}, nil
+}
+
+// Size returns the size of the view box
+func (i icon) Size() (w, h float64) {
+	return i.svg.ViewBox.W, i.svg.ViewBox.H
+}
+
+// Draw renders the view box scaled to r of dst, antialiased
+func (i icon) Draw(dst *image.RGBA, r image.Rectangle) {
+	b := dst.Bounds()
+	i.svg.SetTarget(float64(r.Min.X), float64(r.Min.Y), float64(r.Dx()), float64(r.Dy()))
+	scanner := rasterx.NewScannerGV(b.Dx(), b.Dy(), dst, b)
+	i.svg.Draw(rasterx.NewDasher(b.Dx(), b.Dy(), scanner), 1)
+}
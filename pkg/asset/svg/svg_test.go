@@ -0,0 +1,37 @@
+package svg
+
+import (
+	"image"
+	"path/filepath"
+	"testing"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/asset"
+)
+
+// TestLoadLogo tests rendering the FluidNC logo at panel resolutions
+func TestLoadLogo(t *testing.T) {
+	path := filepath.Join("..", "..", "..", "examples", "fluidnc-logo.svg")
+	tests := []struct {
+		size image.Point
+		mode string
+	}{
+		{image.Pt(32, 32), asset.ModeFit},
+		{image.Pt(64, 32), asset.ModeFit},
+		{image.Pt(16, 16), asset.ModeFill},
+	}
+	for _, tt := range tests {
+		img, err := asset.Load(path, asset.Options{Size: tt.size, Mode: tt.mode})
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if img.Bounds().Size() != tt.size {
+			t.Errorf("Load() size = %v, want %v", img.Bounds().Size(), tt.size)
+		}
+
+		// The F is drawn in red across the middle
+		c := img.RGBAAt(tt.size.X/2-2, tt.size.Y/2)
+		if c.R < 128 || c.G > 64 || c.A < 128 {
+			t.Errorf("%v: center pixel = %v, want red", tt.size, c)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package font
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadBDF loads a face from a BDF file
+func LoadBDF(path string) (*Face, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open font: %w", err)
+	}
+	defer file.Close()
+
+	f, err := ParseBDF(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ParseBDF reads a face in the Glyph Bitmap Distribution Format. Glyphs
+// without a Unicode encoding are skipped.
+func ParseBDF(r io.Reader) (*Face, error) {
+	var (
+		f        = NewFace("", 0, 0)
+		bbox     image.Rectangle
+		ascent   = -1
+		descent  = -1
+		encoding rune
+		advance  int
+		glyph    *Glyph
+		row      = -1
+	)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Bitmap rows are hex, one per line
+		if row >= 0 {
+			if fields[0] == "ENDCHAR" {
+				if encoding >= 0 {
+					f.SetGlyph(encoding, glyph)
+				}
+				row = -1
+				continue
+			}
+			if row >= glyph.Bounds.Dy() {
+				return nil, fmt.Errorf("line %d: too many bitmap rows", n)
+			}
+			bits, err := hex.DecodeString(fields[0])
+			if err != nil || len(bits) < glyph.Stride {
+				return nil, fmt.Errorf("line %d: invalid bitmap row %q", n, fields[0])
+			}
+			copy(glyph.Bits[row*glyph.Stride:], bits[:glyph.Stride])
+			row++
+			continue
+		}
+
+		args, err := atoiAll(fields[1:])
+		switch fields[0] {
+		case "FONT":
+			f.Name = strings.Join(fields[1:], " ")
+			continue
+		case "FONTBOUNDINGBOX", "FONT_ASCENT", "FONT_DESCENT", "ENCODING", "DWIDTH", "BBX":
+			if err != nil || len(args) == 0 {
+				return nil, fmt.Errorf("line %d: invalid %s", n, fields[0])
+			}
+		default:
+			continue
+		}
+
+		switch fields[0] {
+		case "FONTBOUNDINGBOX":
+			if len(args) != 4 {
+				return nil, fmt.Errorf("line %d: invalid FONTBOUNDINGBOX", n)
+			}
+			bbox = bdfBox(args)
+		case "FONT_ASCENT":
+			ascent = args[0]
+		case "FONT_DESCENT":
+			descent = args[0]
+		case "ENCODING":
+			encoding = rune(args[0])
+			advance = bbox.Dx()
+			glyph = nil
+		case "DWIDTH":
+			advance = args[0]
+		case "BBX":
+			if len(args) != 4 {
+				return nil, fmt.Errorf("line %d: invalid BBX", n)
+			}
+			glyph = newGlyph(advance, bdfBox(args))
+			row = 0
+			// BITMAP follows; its rows start on the next line
+			for scanner.Scan() {
+				n++
+				if strings.TrimSpace(scanner.Text()) == "BITMAP" {
+					break
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(f.glyphs) == 0 {
+		return nil, fmt.Errorf("no glyphs")
+	}
+	if ascent < 0 {
+		ascent = -bbox.Min.Y
+	}
+	if descent < 0 {
+		descent = bbox.Max.Y
+	}
+	f.Ascent, f.Descent = ascent, descent
+	f.LineHeight = ascent + descent
+	return f, nil
+}
+
+// bdfBox converts a BDF width, height, x and y offset into bounds relative
+// to the origin with y growing downwards
+func bdfBox(args []int) image.Rectangle {
+	w, h, x, y := args[0], args[1], args[2], args[3]
+	return image.Rect(x, -(y + h), x+w, -y)
+}
+
+// atoiAll parses every field as an integer
+func atoiAll(fields []string) ([]int, error) {
+	ints := make([]int, len(fields))
+	for i, s := range fields {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = v
+	}
+	return ints, nil
+}
@@ -0,0 +1,66 @@
+package font
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+// testBDF is a tiny font with an encoded glyph and an unencoded one
+const testBDF = `STARTFONT 2.1
+FONT -test-tiny-medium-r-normal--4-40-75-75-c-40-iso10646-1
+SIZE 4 75 75
+FONTBOUNDINGBOX 3 4 0 -1
+STARTPROPERTIES 2
+FONT_ASCENT 3
+FONT_DESCENT 1
+ENDPROPERTIES
+CHARS 2
+STARTCHAR j
+ENCODING 106
+SWIDTH 750 0
+DWIDTH 3 0
+BBX 2 4 0 -1
+BITMAP
+40
+40
+40
+80
+ENDCHAR
+STARTCHAR unnamed
+ENCODING -1
+DWIDTH 3 0
+BBX 1 1 0 0
+BITMAP
+80
+ENDCHAR
+ENDFONT
+`
+
+// TestParseBDF tests parsing glyph bitmaps and metrics
+func TestParseBDF(t *testing.T) {
+	f, err := ParseBDF(strings.NewReader(testBDF))
+	if err != nil {
+		t.Fatalf("ParseBDF() error = %v", err)
+	}
+	if f.Ascent != 3 || f.Descent != 1 {
+		t.Errorf("Ascent, Descent = %d, %d, want 3, 1", f.Ascent, f.Descent)
+	}
+	if len(f.glyphs) != 1 {
+		t.Errorf("%d glyphs, want only the encoded one", len(f.glyphs))
+	}
+
+	g := f.Glyph('j')
+	if g == nil || g.Advance != 3 || g.Bounds != image.Rect(0, -3, 2, 1) {
+		t.Fatalf("Glyph(j) = %+v", g)
+	}
+	c := newCanvas(2, 4)
+	f.Draw(c, 0, 0, "j", nil)
+	if want := ".#\n.#\n.#\n#."; c.String() != want {
+		t.Errorf("Draw(j) =\n%s\nwant\n%s", c, want)
+	}
+
+	if _, err := ParseBDF(strings.NewReader("STARTFONT 2.1\nBBX x\n")); err == nil {
+		t.Errorf("ParseBDF() of a broken font error = nil")
+	}
+}
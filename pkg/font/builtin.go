@@ -0,0 +1,330 @@
+package font
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"sync"
+	"unicode"
+)
+
+// builtins are the faces compiled into the package, by name
+var builtins = map[string]*Face{
+	"4x6":   face4x6(),
+	"5x7":   face5x7(),
+	"6x10":  face6x10(),
+	"comic": faceComic(),
+}
+
+// Builtin returns a copy of the named built-in face: 4x6, 5x7, 6x10 or
+// comic (8x12)
+func Builtin(name string) (*Face, error) {
+	f, ok := builtins[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown font %q", name)
+	}
+	return f.clone(), nil
+}
+
+// registered are faces loaded at run time, by name
+var (
+	registered   = make(map[string]*Face)
+	registeredMu sync.RWMutex
+)
+
+// Register makes a face available to Lookup under name, e.g. a TrueType
+// font loaded from the configuration for use in layouts
+func Register(name string, f *Face) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[name] = f
+}
+
+// Lookup returns a copy of the face registered under name, or of the
+// built-in face of that name
+func Lookup(name string) (*Face, error) {
+	registeredMu.RLock()
+	f, ok := registered[name]
+	registeredMu.RUnlock()
+	if ok {
+		return f.clone(), nil
+	}
+	return Builtin(name)
+}
+
+// Builtins returns the names of the built-in faces
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the 5x7 face
+func Default() *Face {
+	return builtins["5x7"].clone()
+}
+
+// Comic returns the 8x12 comic face
+func Comic() *Face {
+	return builtins["comic"].clone()
+}
+
+// face4x6 has 3x5 glyphs in a 4x6 cell; lower case letters are drawn as
+// upper case
+func face4x6() *Face {
+	f := NewFace("4x6", 5, 1)
+	f.LineHeight = 6
+	for r, rows := range glyphs4x6 {
+		f.SetGlyph(r, rowGlyph(rows, -5, 4))
+		if unicode.IsUpper(r) {
+			f.SetGlyph(unicode.ToLower(r), f.glyphs[r])
+		}
+	}
+	return f
+}
+
+// face5x7 has 5x7 glyphs in a 6x8 cell
+func face5x7() *Face {
+	f := NewFace("5x7", 7, 0)
+	for i, cols := range font5x7 {
+		f.SetGlyph(rune(' '+i), columnGlyph(cols))
+	}
+	return f
+}
+
+// face6x10 has the 5x7 glyphs in a 6x10 cell, with real descenders
+func face6x10() *Face {
+	f := face5x7()
+	f.Name = "6x10"
+	f.Ascent, f.Descent, f.LineHeight = 8, 2, 10
+	for r, rows := range descenders6x10 {
+		f.SetGlyph(r, rowGlyph(rows, 2-len(rows), 6))
+	}
+	return f
+}
+
+// faceComic has the 8x12 comic glyphs; runes it lacks are left blank
+func faceComic() *Face {
+	f := NewFace("comic", 10, 2)
+	f.Fallback = ' '
+	for r, rows := range comic8x12 {
+		g := newGlyph(10, image.Rect(0, -10, 8, 2))
+		copy(g.Bits, rows)
+		f.SetGlyph(r, g)
+	}
+	return f
+}
+
+// columnGlyph converts a 5x7 glyph stored by column, top row in the least
+// significant bit
+func columnGlyph(cols [5]byte) *Glyph {
+	g := newGlyph(6, image.Rect(0, -7, 5, 0))
+	for x, bits := range cols {
+		for y := 0; y < 7; y++ {
+			if bits&(1<<y) != 0 {
+				g.set(x, y-7)
+			}
+		}
+	}
+	return g
+}
+
+// rowGlyph converts a glyph drawn as rows of '#' and '.', with its top row
+// top pixels from the baseline
+func rowGlyph(rows []string, top, advance int) *Glyph {
+	g := newGlyph(advance, image.Rect(0, top, len(rows[0]), top+len(rows)))
+	for y, row := range rows {
+		for x, px := range row {
+			if px == '#' {
+				g.set(x, top+y)
+			}
+		}
+	}
+	return g
+}
+
+// font5x7 holds the printable ASCII glyphs from ' ' to '~', one byte per
+// column with the top row in the least significant bit
+var font5x7 = [...][5]byte{
+	{0x00, 0x00, 0x00, 0x00, 0x00}, // ' '
+	{0x00, 0x00, 0x5f, 0x00, 0x00}, // !
+	{0x00, 0x07, 0x00, 0x07, 0x00}, // "
+	{0x14, 0x7f, 0x14, 0x7f, 0x14}, // #
+	{0x24, 0x2a, 0x7f, 0x2a, 0x12}, // $
+	{0x23, 0x13, 0x08, 0x64, 0x62}, // %
+	{0x36, 0x49, 0x56, 0x20, 0x50}, // &
+	{0x00, 0x08, 0x07, 0x03, 0x00}, // '
+	{0x00, 0x1c, 0x22, 0x41, 0x00}, // (
+	{0x00, 0x41, 0x22, 0x1c, 0x00}, // )
+	{0x2a, 0x1c, 0x7f, 0x1c, 0x2a}, // *
+	{0x08, 0x08, 0x3e, 0x08, 0x08}, // +
+	{0x00, 0x50, 0x30, 0x00, 0x00}, // ,
+	{0x08, 0x08, 0x08, 0x08, 0x08}, // -
+	{0x00, 0x60, 0x60, 0x00, 0x00}, // .
+	{0x20, 0x10, 0x08, 0x04, 0x02}, // /
+	{0x3e, 0x51, 0x49, 0x45, 0x3e}, // 0
+	{0x00, 0x42, 0x7f, 0x40, 0x00}, // 1
+	{0x72, 0x49, 0x49, 0x49, 0x46}, // 2
+	{0x21, 0x41, 0x49, 0x4d, 0x33}, // 3
+	{0x18, 0x14, 0x12, 0x7f, 0x10}, // 4
+	{0x27, 0x45, 0x45, 0x45, 0x39}, // 5
+	{0x3c, 0x4a, 0x49, 0x49, 0x31}, // 6
+	{0x41, 0x21, 0x11, 0x09, 0x07}, // 7
+	{0x36, 0x49, 0x49, 0x49, 0x36}, // 8
+	{0x46, 0x49, 0x49, 0x29, 0x1e}, // 9
+	{0x00, 0x36, 0x36, 0x00, 0x00}, // :
+	{0x00, 0x56, 0x36, 0x00, 0x00}, // ;
+	{0x08, 0x14, 0x22, 0x41, 0x00}, // <
+	{0x14, 0x14, 0x14, 0x14, 0x14}, // =
+	{0x00, 0x41, 0x22, 0x14, 0x08}, // >
+	{0x02, 0x01, 0x51, 0x09, 0x06}, // ?
+	{0x3e, 0x41, 0x5d, 0x59, 0x4e}, // @
+	{0x7c, 0x12, 0x11, 0x12, 0x7c}, // A
+	{0x7f, 0x49, 0x49, 0x49, 0x36}, // B
+	{0x3e, 0x41, 0x41, 0x41, 0x22}, // C
+	{0x7f, 0x41, 0x41, 0x22, 0x1c}, // D
+	{0x7f, 0x49, 0x49, 0x49, 0x41}, // E
+	{0x7f, 0x09, 0x09, 0x09, 0x01}, // F
+	{0x3e, 0x41, 0x49, 0x49, 0x7a}, // G
+	{0x7f, 0x08, 0x08, 0x08, 0x7f}, // H
+	{0x00, 0x41, 0x7f, 0x41, 0x00}, // I
+	{0x20, 0x40, 0x41, 0x3f, 0x01}, // J
+	{0x7f, 0x08, 0x14, 0x22, 0x41}, // K
+	{0x7f, 0x40, 0x40, 0x40, 0x40}, // L
+	{0x7f, 0x02, 0x0c, 0x02, 0x7f}, // M
+	{0x7f, 0x04, 0x08, 0x10, 0x7f}, // N
+	{0x3e, 0x41, 0x41, 0x41, 0x3e}, // O
+	{0x7f, 0x09, 0x09, 0x09, 0x06}, // P
+	{0x3e, 0x41, 0x51, 0x21, 0x5e}, // Q
+	{0x7f, 0x09, 0x19, 0x29, 0x46}, // R
+	{0x46, 0x49, 0x49, 0x49, 0x31}, // S
+	{0x01, 0x01, 0x7f, 0x01, 0x01}, // T
+	{0x3f, 0x40, 0x40, 0x40, 0x3f}, // U
+	{0x1f, 0x20, 0x40, 0x20, 0x1f}, // V
+	{0x3f, 0x40, 0x38, 0x40, 0x3f}, // W
+	{0x63, 0x14, 0x08, 0x14, 0x63}, // X
+	{0x07, 0x08, 0x70, 0x08, 0x07}, // Y
+	{0x61, 0x51, 0x49, 0x45, 0x43}, // Z
+	{0x00, 0x7f, 0x41, 0x41, 0x00}, // [
+	{0x02, 0x04, 0x08, 0x10, 0x20}, // \
+	{0x00, 0x41, 0x41, 0x7f, 0x00}, // ]
+	{0x04, 0x02, 0x01, 0x02, 0x04}, // ^
+	{0x40, 0x40, 0x40, 0x40, 0x40}, // _
+	{0x00, 0x01, 0x02, 0x04, 0x00}, // `
+	{0x20, 0x54, 0x54, 0x54, 0x78}, // a
+	{0x7f, 0x48, 0x44, 0x44, 0x38}, // b
+	{0x38, 0x44, 0x44, 0x44, 0x20}, // c
+	{0x38, 0x44, 0x44, 0x48, 0x7f}, // d
+	{0x38, 0x54, 0x54, 0x54, 0x18}, // e
+	{0x08, 0x7e, 0x09, 0x01, 0x02}, // f
+	{0x0c, 0x52, 0x52, 0x52, 0x3e}, // g
+	{0x7f, 0x08, 0x04, 0x04, 0x78}, // h
+	{0x00, 0x44, 0x7d, 0x40, 0x00}, // i
+	{0x20, 0x40, 0x44, 0x3d, 0x00}, // j
+	{0x7f, 0x10, 0x28, 0x44, 0x00}, // k
+	{0x00, 0x41, 0x7f, 0x40, 0x00}, // l
+	{0x7c, 0x04, 0x18, 0x04, 0x78}, // m
+	{0x7c, 0x08, 0x04, 0x04, 0x78}, // n
+	{0x38, 0x44, 0x44, 0x44, 0x38}, // o
+	{0x7c, 0x14, 0x14, 0x14, 0x08}, // p
+	{0x08, 0x14, 0x14, 0x18, 0x7c}, // q
+	{0x7c, 0x08, 0x04, 0x04, 0x08}, // r
+	{0x48, 0x54, 0x54, 0x54, 0x20}, // s
+	{0x04, 0x3f, 0x44, 0x40, 0x20}, // t
+	{0x3c, 0x40, 0x40, 0x20, 0x7c}, // u
+	{0x1c, 0x20, 0x40, 0x20, 0x1c}, // v
+	{0x3c, 0x40, 0x30, 0x40, 0x3c}, // w
+	{0x44, 0x28, 0x10, 0x28, 0x44}, // x
+	{0x0c, 0x50, 0x50, 0x50, 0x3c}, // y
+	{0x44, 0x64, 0x54, 0x4c, 0x44}, // z
+	{0x00, 0x08, 0x36, 0x41, 0x00}, // {
+	{0x00, 0x00, 0x7f, 0x00, 0x00}, // |
+	{0x00, 0x41, 0x36, 0x08, 0x00}, // }
+	{0x08, 0x04, 0x08, 0x10, 0x08}, // ~
+}
+
+// glyphs4x6 are the 3x5 glyphs of the 4x6 face
+var glyphs4x6 = map[rune][]string{
+	' ':  {"...", "...", "...", "...", "..."},
+	'!':  {".#.", ".#.", ".#.", "...", ".#."},
+	'"':  {"#.#", "#.#", "...", "...", "..."},
+	'#':  {"#.#", "###", "#.#", "###", "#.#"},
+	'$':  {".##", "##.", ".#.", ".##", "##."},
+	'%':  {"#..", "..#", ".#.", "#..", "..#"},
+	'&':  {"##.", "##.", "###", "#.#", ".##"},
+	'\'': {".#.", ".#.", "...", "...", "..."},
+	'(':  {"..#", ".#.", ".#.", ".#.", "..#"},
+	')':  {"#..", ".#.", ".#.", ".#.", "#.."},
+	'*':  {"#.#", ".#.", "#.#", "...", "..."},
+	'+':  {"...", ".#.", "###", ".#.", "..."},
+	',':  {"...", "...", "...", ".#.", "#.."},
+	'-':  {"...", "...", "###", "...", "..."},
+	'.':  {"...", "...", "...", "...", ".#."},
+	'/':  {"..#", "..#", ".#.", "#..", "#.."},
+	'0':  {".##", "#.#", "#.#", "#.#", "##."},
+	'1':  {".#.", "##.", ".#.", ".#.", "###"},
+	'2':  {"##.", "..#", ".#.", "#..", "###"},
+	'3':  {"##.", "..#", ".#.", "..#", "##."},
+	'4':  {"#.#", "#.#", "###", "..#", "..#"},
+	'5':  {"###", "#..", "##.", "..#", "##."},
+	'6':  {".##", "#..", "###", "#.#", "###"},
+	'7':  {"###", "..#", ".#.", "#..", "#.."},
+	'8':  {"###", "#.#", "###", "#.#", "###"},
+	'9':  {"###", "#.#", "###", "..#", "##."},
+	':':  {"...", ".#.", "...", ".#.", "..."},
+	';':  {"...", ".#.", "...", ".#.", "#.."},
+	'<':  {"..#", ".#.", "#..", ".#.", "..#"},
+	'=':  {"...", "###", "...", "###", "..."},
+	'>':  {"#..", ".#.", "..#", ".#.", "#.."},
+	'?':  {"###", "..#", ".#.", "...", ".#."},
+	'@':  {".#.", "#.#", "###", "#..", ".##"},
+	'A':  {".#.", "#.#", "###", "#.#", "#.#"},
+	'B':  {"##.", "#.#", "##.", "#.#", "##."},
+	'C':  {".##", "#..", "#..", "#..", ".##"},
+	'D':  {"##.", "#.#", "#.#", "#.#", "##."},
+	'E':  {"###", "#..", "###", "#..", "###"},
+	'F':  {"###", "#..", "###", "#..", "#.."},
+	'G':  {".##", "#..", "###", "#.#", ".##"},
+	'H':  {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I':  {"###", ".#.", ".#.", ".#.", "###"},
+	'J':  {"..#", "..#", "..#", "#.#", ".#."},
+	'K':  {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L':  {"#..", "#..", "#..", "#..", "###"},
+	'M':  {"#.#", "###", "###", "#.#", "#.#"},
+	'N':  {"#.#", "###", "###", "###", "#.#"},
+	'O':  {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P':  {"##.", "#.#", "##.", "#..", "#.."},
+	'Q':  {".#.", "#.#", "#.#", "###", ".##"},
+	'R':  {"##.", "#.#", "###", "##.", "#.#"},
+	'S':  {".##", "#..", ".#.", "..#", "##."},
+	'T':  {"###", ".#.", ".#.", ".#.", ".#."},
+	'U':  {"#.#", "#.#", "#.#", "#.#", ".##"},
+	'V':  {"#.#", "#.#", "#.#", ".#.", ".#."},
+	'W':  {"#.#", "#.#", "###", "###", "#.#"},
+	'X':  {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y':  {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z':  {"###", "..#", ".#.", "#..", "###"},
+	'[':  {"###", "#..", "#..", "#..", "###"},
+	'\\': {"#..", "#..", ".#.", "..#", "..#"},
+	']':  {"###", "..#", "..#", "..#", "###"},
+	'^':  {".#.", "#.#", "...", "...", "..."},
+	'_':  {"...", "...", "...", "...", "###"},
+	'`':  {"#..", ".#.", "...", "...", "..."},
+	'{':  {".##", ".#.", "##.", ".#.", ".##"},
+	'|':  {".#.", ".#.", ".#.", ".#.", ".#."},
+	'}':  {"##.", ".#.", ".##", ".#.", "##."},
+	'~':  {"...", ".##", "##.", "...", "..."},
+}
+
+// descenders6x10 replace the 5x7 glyphs of letters that reach below the
+// baseline in the 6x10 face
+var descenders6x10 = map[rune][]string{
+	'g': {".####", "#...#", "#...#", "#...#", ".####", "....#", ".###."},
+	'j': {"...#.", ".....", "..##.", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'p': {"####.", "#...#", "#...#", "#...#", "####.", "#....", "#...."},
+	'q': {".####", "#...#", "#...#", "#...#", ".####", "....#", "....#"},
+	'y': {"#...#", "#...#", "#...#", "#..##", ".##.#", "....#", ".###."},
+}
@@ -0,0 +1,609 @@
+package font
+
+// comic8x12 is an 8x12 font with rounded, Comic Sans-like styling. Each
+// glyph is 12 rows, most significant bit leftmost; the last two rows are
+// below the baseline.
+var comic8x12 = map[rune][]byte{
+	'A': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b11111111,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b00000000,
+		0b00000000,
+	},
+	'B': {
+		0b11111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b11111100,
+		0b00000000,
+		0b00000000,
+	},
+	'C': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000000,
+		0b11000000,
+		0b11000000,
+		0b11000000,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'D': {
+		0b11111000,
+		0b01101100,
+		0b01100110,
+		0b01100011,
+		0b01100011,
+		0b01100011,
+		0b01100011,
+		0b01100110,
+		0b01101100,
+		0b11111000,
+		0b00000000,
+		0b00000000,
+	},
+	'E': {
+		0b11111110,
+		0b01100010,
+		0b01100000,
+		0b01100000,
+		0b01111100,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100010,
+		0b11111110,
+		0b00000000,
+		0b00000000,
+	},
+	'F': {
+		0b11111110,
+		0b01100010,
+		0b01100000,
+		0b01100000,
+		0b01111100,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b11110000,
+		0b00000000,
+		0b00000000,
+	},
+	'G': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000000,
+		0b11000000,
+		0b11001111,
+		0b11000011,
+		0b11000011,
+		0b01100111,
+		0b00111011,
+		0b00000000,
+		0b00000000,
+	},
+	'H': {
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11111111,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b00000000,
+		0b00000000,
+	},
+	'I': {
+		0b01111100,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b01111100,
+		0b00000000,
+		0b00000000,
+	},
+	'J': {
+		0b00011110,
+		0b00001100,
+		0b00001100,
+		0b00001100,
+		0b00001100,
+		0b00001100,
+		0b11001100,
+		0b11001100,
+		0b01101100,
+		0b00111000,
+		0b00000000,
+		0b00000000,
+	},
+	'K': {
+		0b11100111,
+		0b01100110,
+		0b01100100,
+		0b01101000,
+		0b01110000,
+		0b01111000,
+		0b01101100,
+		0b01100110,
+		0b01100011,
+		0b11100001,
+		0b00000000,
+		0b00000000,
+	},
+	'L': {
+		0b11110000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b01100001,
+		0b01100011,
+		0b11111111,
+		0b00000000,
+		0b00000000,
+	},
+	'M': {
+		0b11000011,
+		0b11100111,
+		0b11111111,
+		0b11011011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b00000000,
+		0b00000000,
+	},
+	'N': {
+		0b11000011,
+		0b11100011,
+		0b11110011,
+		0b11011011,
+		0b11001111,
+		0b11000111,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b00000000,
+		0b00000000,
+	},
+	'O': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'P': {
+		0b11111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01111100,
+		0b01100000,
+		0b01100000,
+		0b01100000,
+		0b11110000,
+		0b00000000,
+		0b00000000,
+	},
+	'Q': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11001011,
+		0b11000111,
+		0b01100110,
+		0b00111101,
+		0b00000000,
+		0b00000000,
+	},
+	'R': {
+		0b11111100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b01111100,
+		0b01101100,
+		0b01100110,
+		0b01100110,
+		0b01100110,
+		0b11100110,
+		0b00000000,
+		0b00000000,
+	},
+	'S': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b01100000,
+		0b00111000,
+		0b00001100,
+		0b00000110,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'T': {
+		0b11111111,
+		0b10110110,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b01111000,
+		0b00000000,
+		0b00000000,
+	},
+	'U': {
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'V': {
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+	},
+	'W': {
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11000011,
+		0b11011011,
+		0b11111111,
+		0b01100110,
+		0b01100110,
+		0b00000000,
+		0b00000000,
+	},
+	'X': {
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00011000,
+		0b00011000,
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b00000000,
+		0b00000000,
+	},
+	'Y': {
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'Z': {
+		0b11111111,
+		0b11000111,
+		0b10001100,
+		0b00011000,
+		0b00110000,
+		0b01100000,
+		0b11000000,
+		0b11000011,
+		0b11100111,
+		0b11111111,
+		0b00000000,
+		0b00000000,
+	},
+	'0': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000111,
+		0b11001111,
+		0b11011011,
+		0b11110011,
+		0b11100011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'1': {
+		0b00110000,
+		0b01110000,
+		0b11110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b11111100,
+		0b00000000,
+		0b00000000,
+	},
+	'2': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b00000011,
+		0b00000110,
+		0b00001100,
+		0b00011000,
+		0b00110000,
+		0b01100000,
+		0b11111111,
+		0b00000000,
+		0b00000000,
+	},
+	'3': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b00000011,
+		0b00011110,
+		0b00011110,
+		0b00000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'4': {
+		0b00001100,
+		0b00011100,
+		0b00111100,
+		0b01101100,
+		0b11001100,
+		0b11111111,
+		0b00001100,
+		0b00001100,
+		0b00001100,
+		0b00011110,
+		0b00000000,
+		0b00000000,
+	},
+	'5': {
+		0b11111111,
+		0b11000000,
+		0b11000000,
+		0b11000000,
+		0b11111100,
+		0b00000110,
+		0b00000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'6': {
+		0b00111100,
+		0b01100110,
+		0b11000000,
+		0b11000000,
+		0b11111100,
+		0b11000110,
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'7': {
+		0b11111111,
+		0b11000011,
+		0b10000110,
+		0b00001100,
+		0b00011000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00110000,
+		0b00000000,
+		0b00000000,
+	},
+	'8': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b01111110,
+		0b01111110,
+		0b11000011,
+		0b11000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	'9': {
+		0b00111100,
+		0b01100110,
+		0b11000011,
+		0b11000011,
+		0b01100111,
+		0b00111111,
+		0b00000011,
+		0b00000011,
+		0b01100110,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	' ': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+	},
+	'!': {
+		0b00011000,
+		0b00111100,
+		0b00111100,
+		0b00111100,
+		0b00111100,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+	},
+	'.': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00111100,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
+	',': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00111000,
+		0b00111000,
+		0b00011000,
+		0b00110000,
+		0b00000000,
+	},
+	':': {
+		0b00000000,
+		0b00000000,
+		0b00111100,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+		0b00111100,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+	},
+	'-': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b11111111,
+		0b11111111,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+	},
+	'+': {
+		0b00000000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b11111111,
+		0b11111111,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+	},
+}
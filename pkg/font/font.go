@@ -0,0 +1,266 @@
+// Package font draws bitmap fonts onto LED matrices and frame buffers
+package font
+
+import (
+	"image"
+	"image/color"
+)
+
+// Canvas is anything pixels can be set on, such as a frame buffer
+type Canvas interface {
+	Set(x, y int, c color.Color)
+}
+
+// Blender is a canvas that can composite partially covered pixels of
+// antialiased glyphs over what is already drawn
+type Blender interface {
+	Blend(x, y int, c color.Color)
+}
+
+// Glyph is the bitmap of one character
+type Glyph struct {
+	// Advance is the distance from this glyph's origin to the next one
+	Advance int
+	// Bounds is the bitmap's box relative to the origin on the baseline,
+	// with y growing downwards
+	Bounds image.Rectangle
+	// Bits holds the rows top to bottom, most significant bit first,
+	// Stride bytes per row
+	Bits   []byte
+	Stride int
+	// Alpha holds the coverage of antialiased glyphs, one byte per pixel
+	// row by row; Bits is unused when it is set
+	Alpha []uint8
+}
+
+// Lit reports whether the pixel at (x, y) relative to the origin is set
+func (g *Glyph) Lit(x, y int) bool {
+	return g.Coverage(x, y) != 0
+}
+
+// Coverage returns how much of the pixel at (x, y) relative to the origin
+// is covered, from 0 to 0xff
+func (g *Glyph) Coverage(x, y int) uint8 {
+	if !(image.Point{x, y}.In(g.Bounds)) {
+		return 0
+	}
+	x -= g.Bounds.Min.X
+	y -= g.Bounds.Min.Y
+	if g.Alpha != nil {
+		return g.Alpha[y*g.Bounds.Dx()+x]
+	}
+	if g.Bits[y*g.Stride+x/8]&(0x80>>(x%8)) == 0 {
+		return 0
+	}
+	return 0xff
+}
+
+// Face is a font at one size: a cache of decoded glyphs and their metrics
+type Face struct {
+	Name string
+	// Ascent and Descent are the pixels above and below the baseline
+	Ascent  int
+	Descent int
+	// LineHeight is the distance between the baselines of two lines
+	LineHeight int
+	// Fallback is drawn for runes the face has no glyph for
+	Fallback rune
+	glyphs   map[rune]*Glyph
+	kerning  map[[2]rune]int
+}
+
+// NewFace creates an empty face with the given metrics
+func NewFace(name string, ascent, descent int) *Face {
+	return &Face{
+		Name:       name,
+		Ascent:     ascent,
+		Descent:    descent,
+		LineHeight: ascent + descent + 1,
+		Fallback:   '?',
+		glyphs:     make(map[rune]*Glyph),
+		kerning:    make(map[[2]rune]int),
+	}
+}
+
+// Height returns the height of a line of text without line spacing
+func (f *Face) Height() int {
+	return f.Ascent + f.Descent
+}
+
+// SetGlyph adds or replaces the glyph for r
+func (f *Face) SetGlyph(r rune, g *Glyph) {
+	f.glyphs[r] = g
+}
+
+// Glyph returns the glyph for r, the fallback glyph if there is none, or
+// nil if the face has neither
+func (f *Face) Glyph(r rune) *Glyph {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	return f.glyphs[f.Fallback]
+}
+
+// SetKerning adjusts the advance between a and b by adjust pixels
+func (f *Face) SetKerning(a, b rune, adjust int) {
+	if adjust == 0 {
+		delete(f.kerning, [2]rune{a, b})
+		return
+	}
+	f.kerning[[2]rune{a, b}] = adjust
+}
+
+// Kern returns the adjustment of the advance between a and b
+func (f *Face) Kern(a, b rune) int {
+	return f.kerning[[2]rune{a, b}]
+}
+
+// Measure returns the width of text in pixels, from the first origin to
+// the right edge of the last glyph
+func (f *Face) Measure(text string) int {
+	x, right := 0, 0
+	prev := rune(-1)
+	for _, r := range text {
+		g := f.Glyph(r)
+		if g == nil {
+			continue
+		}
+		if prev >= 0 {
+			x += f.Kern(prev, r)
+		}
+		if edge := x + g.Bounds.Max.X; edge > right {
+			right = edge
+		}
+		x += g.Advance
+		prev = r
+	}
+	return right
+}
+
+// Draw draws text with the top of the line at y and returns the x position
+// of the next glyph. Pixels outside the canvas are left to it to clip.
+func (f *Face) Draw(dst Canvas, x, y int, text string, c color.Color) int {
+	baseline := y + f.Ascent
+	prev := rune(-1)
+	for _, r := range text {
+		g := f.Glyph(r)
+		if g == nil {
+			continue
+		}
+		if prev >= 0 {
+			x += f.Kern(prev, r)
+		}
+		for gy := g.Bounds.Min.Y; gy < g.Bounds.Max.Y; gy++ {
+			for gx := g.Bounds.Min.X; gx < g.Bounds.Max.X; gx++ {
+				switch a := g.Coverage(gx, gy); a {
+				case 0:
+				case 0xff:
+					dst.Set(x+gx, baseline+gy, c)
+				default:
+					setCoverage(dst, x+gx, baseline+gy, c, a)
+				}
+			}
+		}
+		x += g.Advance
+		prev = r
+	}
+	return x
+}
+
+// setCoverage draws c partially covering a pixel: blended over the canvas
+// if it supports it, otherwise dimmed as if drawn over black
+func setCoverage(dst Canvas, x, y int, c color.Color, a uint8) {
+	r, g, b, alpha := c.RGBA()
+	scale := func(v uint32) uint16 { return uint16(v * uint32(a) / 0xff) }
+	partial := color.RGBA64{R: scale(r), G: scale(g), B: scale(b), A: scale(alpha)}
+	if blender, ok := dst.(Blender); ok {
+		blender.Blend(x, y, partial)
+		return
+	}
+	partial.A = 0xffff
+	dst.Set(x, y, partial)
+}
+
+// Proportional returns a copy of the face in which every glyph advances by
+// the width of its ink plus spacing, fitting more text on a small panel
+func (f *Face) Proportional(spacing int) *Face {
+	p := f.clone()
+	p.Name = f.Name + "p"
+	for r, g := range f.glyphs {
+		ink := g.inkBounds()
+		if ink.Empty() {
+			// Keep spaces
+			continue
+		}
+		trimmed := g.crop(image.Rect(ink.Min.X, g.Bounds.Min.Y, ink.Max.X, g.Bounds.Max.Y))
+		trimmed.Bounds = trimmed.Bounds.Sub(image.Pt(ink.Min.X, 0))
+		trimmed.Advance = ink.Dx() + spacing
+		p.glyphs[r] = trimmed
+	}
+	return p
+}
+
+// crop returns the part of the glyph inside r
+func (g *Glyph) crop(r image.Rectangle) *Glyph {
+	c := newGlyph(g.Advance, r)
+	if g.Alpha != nil {
+		c.Bits, c.Stride = nil, 0
+		c.Alpha = make([]uint8, r.Dx()*r.Dy())
+	}
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if a := g.Coverage(x, y); a != 0 && c.Alpha != nil {
+				c.Alpha[(y-r.Min.Y)*r.Dx()+x-r.Min.X] = a
+			} else if a != 0 {
+				c.set(x, y)
+			}
+		}
+	}
+	return c
+}
+
+// newGlyph returns a glyph with an empty bitmap covering bounds
+func newGlyph(advance int, bounds image.Rectangle) *Glyph {
+	stride := (bounds.Dx() + 7) / 8
+	return &Glyph{
+		Advance: advance,
+		Bounds:  bounds,
+		Bits:    make([]byte, stride*bounds.Dy()),
+		Stride:  stride,
+	}
+}
+
+// set lights the pixel at (x, y) relative to the origin
+func (g *Glyph) set(x, y int) {
+	x -= g.Bounds.Min.X
+	y -= g.Bounds.Min.Y
+	g.Bits[y*g.Stride+x/8] |= 0x80 >> (x % 8)
+}
+
+// inkBounds returns the columns of the glyph that have pixels set
+func (g *Glyph) inkBounds() image.Rectangle {
+	ink := image.Rectangle{}
+	for y := g.Bounds.Min.Y; y < g.Bounds.Max.Y; y++ {
+		for x := g.Bounds.Min.X; x < g.Bounds.Max.X; x++ {
+			if g.Lit(x, y) {
+				ink = ink.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return ink
+}
+
+// clone returns a copy of the face sharing the glyphs, which are never
+// modified in place
+func (f *Face) clone() *Face {
+	c := *f
+	c.glyphs = make(map[rune]*Glyph, len(f.glyphs))
+	for r, g := range f.glyphs {
+		c.glyphs[r] = g
+	}
+	c.kerning = make(map[[2]rune]int, len(f.kerning))
+	for k, v := range f.kerning {
+		c.kerning[k] = v
+	}
+	return &c
+}
@@ -0,0 +1,156 @@
+package font
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// canvas records lit pixels as a picture of '#' and '.'
+type canvas struct {
+	rows [][]byte
+}
+
+// newCanvas returns an empty canvas of the given size
+func newCanvas(w, h int) *canvas {
+	c := &canvas{}
+	for y := 0; y < h; y++ {
+		c.rows = append(c.rows, []byte(strings.Repeat(".", w)))
+	}
+	return c
+}
+
+// Set lights the pixel at (x, y)
+func (c *canvas) Set(x, y int, _ color.Color) {
+	if y >= 0 && y < len(c.rows) && x >= 0 && x < len(c.rows[y]) {
+		c.rows[y][x] = '#'
+	}
+}
+
+// String returns the picture, one line per row
+func (c *canvas) String() string {
+	var lines []string
+	for _, row := range c.rows {
+		lines = append(lines, string(row))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TestBuiltins tests the metrics and measurements of the built-in faces
+func TestBuiltins(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		text   string
+		width  int
+	}{
+		{"4x6", 6, "X:1.5", 19},
+		{"5x7", 7, "X:1.5", 29},
+		{"6x10", 10, "X:1.5", 29},
+		{"comic", 12, "AB", 18},
+	}
+	for _, tt := range tests {
+		f, err := Builtin(tt.name)
+		if err != nil {
+			t.Fatalf("Builtin(%q) error = %v", tt.name, err)
+		}
+		if got := f.Height(); got != tt.height {
+			t.Errorf("%s Height() = %d, want %d", tt.name, got, tt.height)
+		}
+		if got := f.Measure(tt.text); got != tt.width {
+			t.Errorf("%s Measure(%q) = %d, want %d", tt.name, tt.text, got, tt.width)
+		}
+	}
+
+	if _, err := Builtin("9x15"); err == nil {
+		t.Errorf("Builtin(9x15) error = nil")
+	}
+}
+
+// TestDraw tests drawing relative to the top of the line, with descenders
+// below the baseline
+func TestDraw(t *testing.T) {
+	f, _ := Builtin("6x10")
+	c := newCanvas(12, 10)
+	if x := f.Draw(c, 0, 0, "Ip", color.White); x != 12 {
+		t.Errorf("Draw() = %d, want 12", x)
+	}
+
+	// The descender of p reaches the last row of the line
+	want := strings.Join([]string{
+		"............",
+		".###........",
+		"..#.........",
+		"..#...####..",
+		"..#...#...#.",
+		"..#...#...#.",
+		"..#...#...#.",
+		".###..####..",
+		"......#.....",
+		"......#.....",
+	}, "\n")
+	if got := c.String(); got != want {
+		t.Errorf("Draw() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestKerningAndProportional tests advances adjusted by kerning pairs and
+// by proportional spacing
+func TestKerningAndProportional(t *testing.T) {
+	f := Default()
+	f.SetKerning('T', 'o', -1)
+	if got := f.Measure("To"); got != 10 {
+		t.Errorf("Measure(To) with kerning = %d, want 10", got)
+	}
+	if got := Default().Measure("To"); got != 11 {
+		t.Errorf("kerning leaked into a new copy of the face")
+	}
+
+	p := f.Proportional(1)
+	if got := p.Measure("1.1"); got != 10 {
+		t.Errorf("proportional Measure(1.1) = %d, want 10", got)
+	}
+	if g := p.Glyph('.'); g.Bounds != image.Rect(0, -7, 2, 0) || g.Advance != 3 {
+		t.Errorf("proportional '.' = %v advance %d", g.Bounds, g.Advance)
+	}
+}
+
+// blendCanvas records the colors set and blended into it
+type blendCanvas struct {
+	set     []color.Color
+	blended []color.Color
+}
+
+// Set records a color set directly
+func (c *blendCanvas) Set(x, y int, col color.Color) {
+	c.set = append(c.set, col)
+}
+
+// Blend records a blended color
+func (c *blendCanvas) Blend(x, y int, col color.Color) {
+	c.blended = append(c.blended, col)
+}
+
+// TestDrawCoverage tests that partially covered pixels are blended on
+// canvases that support it and dimmed on others
+func TestDrawCoverage(t *testing.T) {
+	f := NewFace("aa", 1, 0)
+	f.SetGlyph('a', &Glyph{Advance: 2, Bounds: image.Rect(0, -1, 2, 0), Alpha: []uint8{0xff, 0x80}})
+	white := color.RGBA{255, 255, 255, 255}
+
+	b := &blendCanvas{}
+	f.Draw(b, 0, 0, "a", white)
+	if len(b.set) != 1 || len(b.blended) != 1 {
+		t.Fatalf("set %d and blended %d pixels, want 1 and 1", len(b.set), len(b.blended))
+	}
+	if _, _, _, a := b.blended[0].RGBA(); a != 0x8080 {
+		t.Errorf("blended alpha = %#x, want 0x8080", a)
+	}
+
+	c := newCanvas(2, 1)
+	f.Draw(c, 0, 0, "a", white)
+	if got := c.String(); got != "##" {
+		t.Errorf("Draw() = %q, want both pixels set", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Package ttf rasterizes TrueType fonts, and OpenType fonts with TrueType
+// outlines, into bitmap faces at the small pixel sizes of LED panels
+package ttf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
+	"github.com/golang/freetype/truetype"
+	xfont "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Hinting modes. freetype hints either fully or not at all, so there is no
+// vertical-only mode.
+const (
+	HintingFull = "full"
+	HintingNone = "none"
+)
+
+// ErrCFF is returned for OpenType fonts with CFF (PostScript) outlines,
+// which the rasterizer cannot read
+var ErrCFF = errors.New("OpenType fonts with CFF outlines are not supported, use one with TrueType outlines")
+
+// Options control how a font is rasterized
+type Options struct {
+	// Size is the em size in pixels
+	Size float64
+	// Hinting is full (the default) or none
+	Hinting string
+	// Levels is the number of brightness levels the panel can show per
+	// pixel; coverage is quantized to it, 2 renders without antialiasing
+	// and 0 keeps full 8-bit coverage
+	Levels int
+	// Runes are the characters rasterized, printable ASCII if empty
+	Runes []rune
+}
+
+// Load rasterizes the font file at path
+func Load(path string, opts Options) (*font.Face, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font: %w", err)
+	}
+	f, err := Parse(data, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse rasterizes a font with TrueType outlines. OpenType fonts with CFF
+// outlines, which most .otf files have, fail with ErrCFF.
+func Parse(data []byte, opts Options) (*font.Face, error) {
+	if opts.Size <= 0 {
+		return nil, fmt.Errorf("invalid size %v", opts.Size)
+	}
+	hinting, err := parseHinting(opts.Hinting)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("OTTO")) {
+		return nil, ErrCFF
+	}
+
+	parsed, err := truetype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse font: %w", err)
+	}
+	src := truetype.NewFace(parsed, &truetype.Options{
+		Size:    opts.Size,
+		DPI:     72,
+		Hinting: hinting,
+	})
+	defer src.Close()
+
+	metrics := src.Metrics()
+	face := font.NewFace(parsed.Name(truetype.NameIDFontFullName), metrics.Ascent.Ceil(), metrics.Descent.Ceil())
+	face.LineHeight = metrics.Height.Ceil()
+
+	runes := opts.Runes
+	if len(runes) == 0 {
+		for r := rune(' '); r <= '~'; r++ {
+			runes = append(runes, r)
+		}
+	}
+	var found []rune
+	for _, r := range runes {
+		if parsed.Index(r) == 0 {
+			continue
+		}
+		dr, mask, maskp, advance, ok := src.Glyph(fixed.Point26_6{}, r)
+		if !ok {
+			continue
+		}
+		face.SetGlyph(r, glyph(dr, mask, maskp, advance.Round(), opts.Levels))
+		found = append(found, r)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("font has none of the requested characters")
+	}
+
+	for _, a := range found {
+		for _, b := range found {
+			if k := src.Kern(a, b).Round(); k != 0 {
+				face.SetKerning(a, b, k)
+			}
+		}
+	}
+	return face, nil
+}
+
+// glyph copies a rasterized mask into a glyph, quantizing its coverage
+func glyph(dr image.Rectangle, mask image.Image, maskp image.Point, advance, levels int) *font.Glyph {
+	g := &font.Glyph{
+		Advance: advance,
+		Bounds:  dr,
+		Alpha:   make([]uint8, dr.Dx()*dr.Dy()),
+	}
+	for y := 0; y < dr.Dy(); y++ {
+		for x := 0; x < dr.Dx(); x++ {
+			a := color.AlphaModel.Convert(mask.At(maskp.X+x, maskp.Y+y)).(color.Alpha).A
+			g.Alpha[y*dr.Dx()+x] = quantize(a, levels)
+		}
+	}
+	return g
+}
+
+// quantize maps coverage to the nearest of levels evenly spaced values
+func quantize(a uint8, levels int) uint8 {
+	if levels <= 0 || levels > 256 {
+		return a
+	}
+	if levels == 1 {
+		levels = 2
+	}
+	steps := levels - 1
+	step := (int(a)*steps + 127) / 255
+	return uint8(step * 255 / steps)
+}
+
+// parseHinting converts a hinting mode name
+func parseHinting(name string) (xfont.Hinting, error) {
+	switch name {
+	case "", HintingFull:
+		return xfont.HintingFull, nil
+	case HintingNone:
+		return xfont.HintingNone, nil
+	}
+	return xfont.HintingNone, fmt.Errorf("unknown hinting %q", name)
+}
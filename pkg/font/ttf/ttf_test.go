@@ -0,0 +1,83 @@
+package ttf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// TestQuantize tests mapping coverage to the panel's brightness levels
+func TestQuantize(t *testing.T) {
+	tests := []struct {
+		a      uint8
+		levels int
+		want   uint8
+	}{
+		{200, 0, 200},
+		{127, 2, 0},
+		{128, 2, 255},
+		{100, 4, 85},
+		{200, 4, 170},
+		{255, 16, 255},
+	}
+	for _, tt := range tests {
+		if got := quantize(tt.a, tt.levels); got != tt.want {
+			t.Errorf("quantize(%d, %d) = %d, want %d", tt.a, tt.levels, got, tt.want)
+		}
+	}
+}
+
+// TestParse tests rasterizing a font at a small size
+func TestParse(t *testing.T) {
+	face, err := Parse(gomono.TTF, Options{Size: 10, Levels: 2})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// The face takes its metrics from the font, rounded up to whole pixels
+	parsed, _ := truetype.Parse(gomono.TTF)
+	metrics := truetype.NewFace(parsed, &truetype.Options{Size: 10, DPI: 72}).Metrics()
+	if face.Ascent != metrics.Ascent.Ceil() || face.Descent != metrics.Descent.Ceil() {
+		t.Errorf("Ascent, Descent = %d, %d, want %d, %d", face.Ascent, face.Descent, metrics.Ascent.Ceil(), metrics.Descent.Ceil())
+	}
+	if face.LineHeight != metrics.Height.Ceil() {
+		t.Errorf("LineHeight = %d, want %d", face.LineHeight, metrics.Height.Ceil())
+	}
+	if face.Height() < 10 {
+		t.Errorf("Height() = %d, want at least the em size", face.Height())
+	}
+
+	// A monospaced font advances the same for every digit
+	if a1, a8 := face.Glyph('1').Advance, face.Glyph('8').Advance; a1 == 0 || a1 != a8 {
+		t.Errorf("advances of 1 and 8 = %d, %d, want equal", a1, a8)
+	}
+
+	// Without antialiasing every pixel is either on or off
+	g := face.Glyph('8')
+	for _, a := range g.Alpha {
+		if a != 0 && a != 0xff {
+			t.Fatalf("coverage %d with 2 levels", a)
+		}
+	}
+
+	if _, err := Parse(gomono.TTF, Options{}); err == nil {
+		t.Errorf("Parse() without a size error = nil")
+	}
+}
+
+// TestParseCFF tests that OpenType fonts with CFF outlines are rejected
+// with ErrCFF rather than a parse error
+func TestParseCFF(t *testing.T) {
+	// An sfnt header tagged OTTO, as CFF-flavoured OpenType fonts start
+	data := append([]byte("OTTO"), make([]byte, 8)...)
+	if _, err := Parse(data, Options{Size: 10}); !errors.Is(err, ErrCFF) {
+		t.Errorf("Parse() of a CFF font error = %v, want ErrCFF", err)
+	}
+
+	// A font with TrueType outlines still loads
+	if _, err := Parse(gomono.TTF, Options{Size: 10}); err != nil {
+		t.Errorf("Parse() error = %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package framebuffer provides an RGB888 software canvas shared by the
+// display backends
+package framebuffer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// FrameBuffer is an opaque RGB888 canvas implementing draw.Image. It tracks
+// the region changed since the last ResetDirty so backends can update only
+// what changed. A FrameBuffer is not safe for concurrent use.
+type FrameBuffer struct {
+	// Pix holds the pixels in R, G, B order, row by row
+	Pix []uint8
+	// Stride is the distance in bytes between vertically adjacent pixels
+	Stride int
+	// Rect is the bounds of the canvas
+	Rect image.Rectangle
+	// dirty is shared with sub-images of the same canvas
+	dirty *image.Rectangle
+}
+
+// New creates a black canvas of the given size; all of it starts dirty
+func New(width, height int) *FrameBuffer {
+	if width < 0 {
+		width = 0
+	}
+	if height < 0 {
+		height = 0
+	}
+
+	rect := image.Rect(0, 0, width, height)
+	dirty := rect
+	return &FrameBuffer{
+		Pix:    make([]uint8, 3*width*height),
+		Stride: 3 * width,
+		Rect:   rect,
+		dirty:  &dirty,
+	}
+}
+
+// ColorModel returns the color model of the canvas
+func (fb *FrameBuffer) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds returns the bounds of the canvas
+func (fb *FrameBuffer) Bounds() image.Rectangle {
+	return fb.Rect
+}
+
+// Width returns the width of the canvas
+func (fb *FrameBuffer) Width() int {
+	return fb.Rect.Dx()
+}
+
+// Height returns the height of the canvas
+func (fb *FrameBuffer) Height() int {
+	return fb.Rect.Dy()
+}
+
+// PixOffset returns the index of the first byte of the pixel at (x, y)
+func (fb *FrameBuffer) PixOffset(x, y int) int {
+	return (y-fb.Rect.Min.Y)*fb.Stride + (x-fb.Rect.Min.X)*3
+}
+
+// At returns the color of the pixel at (x, y)
+func (fb *FrameBuffer) At(x, y int) color.Color {
+	return fb.RGBAt(x, y)
+}
+
+// RGBAt returns the color of the pixel at (x, y), black outside the bounds
+func (fb *FrameBuffer) RGBAt(x, y int) color.RGBA {
+	if !(image.Point{x, y}.In(fb.Rect)) {
+		return color.RGBA{A: 0xff}
+	}
+	i := fb.PixOffset(x, y)
+	return color.RGBA{R: fb.Pix[i], G: fb.Pix[i+1], B: fb.Pix[i+2], A: 0xff}
+}
+
+// Set replaces the pixel at (x, y). Alpha is ignored as if c were drawn
+// over black; use Blend to composite over the current pixel.
+func (fb *FrameBuffer) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(fb.Rect)) {
+		return
+	}
+	r, g, b, _ := c.RGBA()
+	fb.setRGB(x, y, uint8(r>>8), uint8(g>>8), uint8(b>>8))
+}
+
+// SetRGB replaces the pixel at (x, y)
+func (fb *FrameBuffer) SetRGB(x, y int, r, g, b uint8) {
+	if !(image.Point{x, y}.In(fb.Rect)) {
+		return
+	}
+	fb.setRGB(x, y, r, g, b)
+}
+
+// setRGB writes an in-bounds pixel and marks it dirty
+func (fb *FrameBuffer) setRGB(x, y int, r, g, b uint8) {
+	i := fb.PixOffset(x, y)
+	fb.Pix[i], fb.Pix[i+1], fb.Pix[i+2] = r, g, b
+	fb.MarkDirty(image.Rect(x, y, x+1, y+1))
+}
+
+// Blend composites c over the pixel at (x, y)
+func (fb *FrameBuffer) Blend(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(fb.Rect)) {
+		return
+	}
+
+	// c is alpha-premultiplied, so out = c + dst*(1-a)
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return
+	}
+	i := fb.PixOffset(x, y)
+	inv := 0xffff - a
+	blend := func(src uint32, dst uint8) uint8 {
+		return uint8((src + uint32(dst)*0x101*inv/0xffff) >> 8)
+	}
+	fb.setRGB(x, y, blend(r, fb.Pix[i]), blend(g, fb.Pix[i+1]), blend(b, fb.Pix[i+2]))
+}
+
+// Fill replaces every pixel of the canvas with c
+func (fb *FrameBuffer) Fill(c color.Color) {
+	r, g, b, _ := c.RGBA()
+	fill := [3]uint8{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8)}
+	for y := fb.Rect.Min.Y; y < fb.Rect.Max.Y; y++ {
+		row := fb.Pix[fb.PixOffset(fb.Rect.Min.X, y):fb.PixOffset(fb.Rect.Max.X, y)]
+		for i := 0; i < len(row); i += 3 {
+			copy(row[i:i+3], fill[:])
+		}
+	}
+	fb.MarkDirty(fb.Rect)
+}
+
+// Clear sets every pixel of the canvas to black
+func (fb *FrameBuffer) Clear() {
+	fb.Fill(color.Black)
+}
+
+// Clone returns a copy of the canvas with its own pixels and dirty region
+func (fb *FrameBuffer) Clone() *FrameBuffer {
+	clone := New(fb.Width(), fb.Height())
+	for y := fb.Rect.Min.Y; y < fb.Rect.Max.Y; y++ {
+		copy(clone.Pix[clone.PixOffset(0, y-fb.Rect.Min.Y):], fb.Pix[fb.PixOffset(fb.Rect.Min.X, y):fb.PixOffset(fb.Rect.Max.X, y)])
+	}
+	*clone.dirty = fb.Dirty().Intersect(fb.Rect).Sub(fb.Rect.Min)
+	return clone
+}
+
+// SubImage returns the part of the canvas inside r. It shares pixels and
+// dirty tracking with the original.
+func (fb *FrameBuffer) SubImage(r image.Rectangle) *FrameBuffer {
+	r = r.Intersect(fb.Rect)
+	if r.Empty() {
+		return &FrameBuffer{dirty: fb.dirty}
+	}
+	i := fb.PixOffset(r.Min.X, r.Min.Y)
+	return &FrameBuffer{
+		Pix:    fb.Pix[i:],
+		Stride: fb.Stride,
+		Rect:   r,
+		dirty:  fb.dirty,
+	}
+}
+
+// Blit copies the sr part of src to the canvas with its top-left corner at
+// dp, replacing what was there
+func (fb *FrameBuffer) Blit(dp image.Point, src image.Image, sr image.Rectangle) {
+	fb.Draw(sr.Sub(sr.Min).Add(dp), src, sr.Min, draw.Src)
+}
+
+// Composite draws the sr part of src over the canvas with its top-left
+// corner at dp, blending by the source alpha
+func (fb *FrameBuffer) Composite(dp image.Point, src image.Image, sr image.Rectangle) {
+	fb.Draw(sr.Sub(sr.Min).Add(dp), src, sr.Min, draw.Over)
+}
+
+// Draw draws src into r of the canvas like draw.Draw and marks r dirty
+func (fb *FrameBuffer) Draw(r image.Rectangle, src image.Image, sp image.Point, op draw.Op) {
+	// Clip r to the canvas and the source as draw.Draw would
+	orig := r.Min
+	r = r.Intersect(fb.Rect).Intersect(src.Bounds().Add(orig.Sub(sp)))
+	if r.Empty() {
+		return
+	}
+	sp = sp.Add(r.Min.Sub(orig))
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := src.At(sp.X+x-r.Min.X, sp.Y+y-r.Min.Y)
+			if op == draw.Over {
+				fb.Blend(x, y, c)
+			} else {
+				fb.Set(x, y, c)
+			}
+		}
+	}
+}
+
+// Dirty returns the region changed since the last ResetDirty
+func (fb *FrameBuffer) Dirty() image.Rectangle {
+	return *fb.dirty
+}
+
+// MarkDirty adds r to the changed region, e.g. after writing to Pix directly
+func (fb *FrameBuffer) MarkDirty(r image.Rectangle) {
+	*fb.dirty = fb.dirty.Union(r)
+}
+
+// ResetDirty marks the whole canvas as unchanged, typically after it has
+// been presented
+func (fb *FrameBuffer) ResetDirty() {
+	*fb.dirty = image.Rectangle{}
+}
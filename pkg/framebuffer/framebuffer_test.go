@@ -0,0 +1,103 @@
+package framebuffer
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+)
+
+// TestSetAndDirty tests pixel access and dirty-region tracking
+func TestSetAndDirty(t *testing.T) {
+	fb := New(8, 4)
+	if got := fb.Dirty(); got != fb.Bounds() {
+		t.Errorf("initial Dirty() = %v, want %v", got, fb.Bounds())
+	}
+
+	fb.ResetDirty()
+	fb.Set(2, 1, red)
+	fb.SetRGB(5, 3, 0, 0, 255)
+	fb.Set(20, 20, red) // out of bounds is ignored
+
+	if got := fb.RGBAt(2, 1); got != red {
+		t.Errorf("RGBAt(2, 1) = %v, want %v", got, red)
+	}
+	if want := image.Rect(2, 1, 6, 4); fb.Dirty() != want {
+		t.Errorf("Dirty() = %v, want %v", fb.Dirty(), want)
+	}
+
+	// The canvas is a draw.Image
+	var _ draw.Image = fb
+}
+
+// TestSubImage tests that sub-images share pixels and dirty tracking
+func TestSubImage(t *testing.T) {
+	fb := New(8, 4)
+	fb.ResetDirty()
+
+	sub := fb.SubImage(image.Rect(4, 0, 8, 4))
+	sub.Fill(green)
+
+	if got := fb.RGBAt(4, 0); got != green {
+		t.Errorf("RGBAt(4, 0) = %v, want %v", got, green)
+	}
+	if got := fb.RGBAt(3, 0); got != (color.RGBA{A: 255}) {
+		t.Errorf("RGBAt(3, 0) = %v, want black", got)
+	}
+	if want := image.Rect(4, 0, 8, 4); fb.Dirty() != want {
+		t.Errorf("Dirty() = %v, want %v", fb.Dirty(), want)
+	}
+}
+
+// TestBlitAndComposite tests copying and alpha compositing images
+func TestBlitAndComposite(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(src, src.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+
+	fb := New(8, 4)
+	fb.Blit(image.Pt(6, 2), src, image.Rect(0, 0, 4, 4))
+	if got := fb.RGBAt(7, 3); got != red {
+		t.Errorf("blitted RGBAt(7, 3) = %v, want %v", got, red)
+	}
+	if got := fb.RGBAt(5, 3); got != (color.RGBA{A: 255}) {
+		t.Errorf("RGBAt(5, 3) = %v, want black", got)
+	}
+
+	// Half-transparent green over red
+	fb.Fill(red)
+	overlay := image.NewUniform(color.NRGBA{G: 255, A: 128})
+	fb.Composite(image.Pt(0, 0), overlay, image.Rect(0, 0, 2, 2))
+
+	got := fb.RGBAt(1, 1)
+	if got.R < 120 || got.R > 135 || got.G < 120 || got.G > 135 || got.B != 0 {
+		t.Errorf("composited RGBAt(1, 1) = %v, want an even red/green mix", got)
+	}
+	if got := fb.RGBAt(2, 2); got != red {
+		t.Errorf("RGBAt(2, 2) = %v, want untouched red", got)
+	}
+}
+
+// TestClone tests that a clone of a sub-image is independent of it
+func TestClone(t *testing.T) {
+	fb := New(8, 4)
+	fb.SetRGB(5, 2, 255, 0, 0)
+	fb.ResetDirty()
+
+	clone := fb.SubImage(image.Rect(4, 2, 8, 4)).Clone()
+	if clone.Bounds() != image.Rect(0, 0, 4, 2) {
+		t.Fatalf("Clone() bounds = %v", clone.Bounds())
+	}
+	if got := clone.RGBAt(1, 0); got != red {
+		t.Errorf("clone RGBAt(1, 0) = %v, want %v", got, red)
+	}
+
+	clone.Set(0, 0, green)
+	if fb.RGBAt(4, 2) == green || !fb.Dirty().Empty() {
+		t.Error("writing to the clone changed the original")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/font"
 	"github.com/fcurrie/fluidnc-led-golang/pkg/gpio"
 	"github.com/fcurrie/fluidnc-led-golang/pkg/mmap"
 	"github.com/fcurrie/fluidnc-led-golang/pkg/pio"
@@ -38,6 +39,7 @@ type RGBMatrix struct {
 	mem        *mmap.MemoryMap
 	mutex      sync.Mutex
 	buffer     []color.Color
+	font       *font.Face
 }
 
 // NewRGBMatrix creates a new RGB matrix display
@@ -73,6 +75,7 @@ func NewRGBMatrix(width, height int, pin int) (*RGBMatrix, error) {
 		pio:    pioState,
 		mem:    mem,
 		buffer: buffer,
+		font:   font.Default(),
 	}, nil
 }
 
@@ -252,18 +255,49 @@ func (m *RGBMatrix) SetImage(img image.Image) error {
 	return m.show()
 }
 
-// SetText sets the display to show text
+// SetText draws text with its top left corner at (x, y) in the current
+// font; call Show to display it
 func (m *RGBMatrix) SetText(text string, x, y int, c color.Color) error {
-	// This is a placeholder - in a real implementation, this would render text
-	// using a font and set the pixels accordingly
-	return fmt.Errorf("SetText not implemented")
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.font.Draw(bufferCanvas{m}, x, y, text, c)
+	return nil
 }
 
-// SetFont sets the font for text rendering
-func (m *RGBMatrix) SetFont(font interface{}) error {
-	// This is a placeholder - in a real implementation, this would set the font
-	// for text rendering
-	return fmt.Errorf("SetFont not implemented")
+// SetFont sets the font for text rendering: a *font.Face or the name of a
+// built-in font
+func (m *RGBMatrix) SetFont(f interface{}) error {
+	var face *font.Face
+	switch f := f.(type) {
+	case *font.Face:
+		face = f
+	case string:
+		var err error
+		if face, err = font.Builtin(f); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported font type %T", f)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.font = face
+	return nil
+}
+
+// bufferCanvas draws into the matrix buffer, clipping to the matrix; the
+// mutex must be held
+type bufferCanvas struct {
+	m *RGBMatrix
+}
+
+// Set sets a pixel of the buffer
+func (b bufferCanvas) Set(x, y int, c color.Color) {
+	if x >= 0 && x < b.m.width && y >= 0 && y < b.m.height {
+		b.m.buffer[y*b.m.width+x] = c
+	}
 }
 
 // SetRotation sets the rotation of the display
@@ -5,6 +5,8 @@ import (
 	"image"
 	"image/color"
 	"sync"
+
+	"github.com/fkcurrie/fluidnc-led-golang/pkg/framebuffer"
 )
 
 // Matrix represents an RGB LED matrix display
@@ -103,6 +105,14 @@ func (m *Matrix) Show() error {
 	return m.strip.Show()
 }
 
+// Present shows a whole frame
+func (m *Matrix) Present(fb *framebuffer.FrameBuffer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.strip.SetImage(fb)
+}
+
 // SetBrightness sets the brightness of the LED matrix
 func (m *Matrix) SetBrightness(brightness int) error {
 	if brightness < 0 || brightness > 255 {
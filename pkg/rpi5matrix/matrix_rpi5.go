@@ -0,0 +1,8 @@
+//go:build rpi5
+
+package rpi5matrix
+
+import "github.com/fkcurrie/fluidnc-led-golang/internal/types"
+
+// Matrix is the hardware backend of the display
+var _ types.Matrix = (*Matrix)(nil)